@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+// DefaultTokenUsername is the username sent with a token. GitHub accepts any
+// username, GitLab expects "oauth2".
+const DefaultTokenUsername = "bot"
+
+// Provider provides the authentication for a remote endpoint.
+type Provider interface {
+	// Auth returns the authentication for the endpoint. If the provider has
+	// no credentials for the endpoint or does not support its protocol, nil
+	// is returned.
+	Auth(ctx context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error)
+}
+
+// Providers asks each provider in order and returns the first authentication
+// found.
+type Providers []Provider
+
+// Auth returns the authentication of the first provider that has credentials
+// for the endpoint. If none has, nil is returned.
+func (p Providers) Auth(ctx context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	for _, provider := range p {
+		method, err := provider.Auth(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if method != nil {
+			return method, nil
+		}
+	}
+	return nil, nil
+}
+
+// Resolve returns the authentication of the provider for the remote URL.
+// Local remotes need no authentication. For all other remotes,
+// release.ErrAuthMissing is returned if the provider has no credentials.
+func Resolve(ctx context.Context, provider Provider, url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse remote url %q: %w", url, err)
+	}
+	if endpoint.Protocol == "file" {
+		return nil, nil
+	}
+	method, err := provider.Auth(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if method == nil {
+		return nil, fmt.Errorf("%w: no credentials found for %s://%s", release.ErrAuthMissing, endpoint.Protocol, endpoint.Host)
+	}
+	return method, nil
+}
+
+// isHTTP returns true if the endpoint is an HTTP or HTTPS remote.
+func isHTTP(endpoint *transport.Endpoint) bool {
+	return endpoint.Protocol == "http" || endpoint.Protocol == "https"
+}
+
+// Token authenticates HTTP remotes with an access token, e.g. a GitHub
+// token, a GitLab token with the username "oauth2" or a Gitea token.
+type Token struct {
+	// Username is sent with the token. Defaults to DefaultTokenUsername.
+	Username string
+	Token    string
+}
+
+// Auth returns the basic authentication of the token for HTTP remotes.
+func (t *Token) Auth(_ context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	if !isHTTP(endpoint) || t.Token == "" {
+		return nil, nil
+	}
+	username := t.Username
+	if username == "" {
+		username = DefaultTokenUsername
+	}
+	return &http.BasicAuth{Username: username, Password: t.Token}, nil
+}
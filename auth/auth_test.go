@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+// testEndpoint parses the url or fails the test.
+func testEndpoint(t *testing.T, url string) *transport.Endpoint {
+	t.Helper()
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoint
+}
+
+func TestToken_Auth(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *Token
+		url   string
+		want  transport.AuthMethod
+	}{
+		{
+			name:  "default username",
+			token: &Token{Token: "secret"},
+			url:   "https://github.com/leonsteinhaeuser/git-tag-bump.git",
+			want:  &http.BasicAuth{Username: DefaultTokenUsername, Password: "secret"},
+		},
+		{
+			name:  "gitlab username",
+			token: &Token{Username: "oauth2", Token: "secret"},
+			url:   "https://gitlab.com/group/project.git",
+			want:  &http.BasicAuth{Username: "oauth2", Password: "secret"},
+		},
+		{
+			name:  "ssh remote",
+			token: &Token{Token: "secret"},
+			url:   "git@github.com:leonsteinhaeuser/git-tag-bump.git",
+		},
+		{
+			name:  "empty token",
+			token: &Token{},
+			url:   "https://github.com/leonsteinhaeuser/git-tag-bump.git",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.token.Auth(context.Background(), testEndpoint(t, tt.url))
+			if err != nil {
+				t.Fatalf("Token.Auth() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Token.Auth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	providers := Providers{
+		&Token{Token: ""},
+		&Token{Username: "oauth2", Token: "secret"},
+	}
+	tests := []struct {
+		name     string
+		provider Provider
+		url      string
+		want     transport.AuthMethod
+		wantErr  error
+	}{
+		{
+			name:     "first provider with credentials",
+			provider: providers,
+			url:      "https://gitlab.com/group/project.git",
+			want:     &http.BasicAuth{Username: "oauth2", Password: "secret"},
+		},
+		{
+			name:     "local remote",
+			provider: Providers{},
+			url:      "/tmp/remote.git",
+		},
+		{
+			name:     "file remote",
+			provider: Providers{},
+			url:      "file:///tmp/remote.git",
+		},
+		{
+			name:     "no credentials",
+			provider: providers,
+			url:      "git@github.com:leonsteinhaeuser/git-tag-bump.git",
+			wantErr:  release.ErrAuthMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), tt.provider, tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
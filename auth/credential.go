@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// CredentialHelper authenticates HTTP remotes with the credential helpers
+// configured in git, by running git credential fill.
+type CredentialHelper struct {
+	// Git is the path of the git binary. Defaults to "git".
+	Git string
+	// Dir is the working directory of git, so the repository config is used.
+	Dir string
+}
+
+// Auth returns the basic authentication of the configured credential helpers
+// for HTTP remotes. If no helper has credentials, nil is returned. git never
+// prompts for credentials.
+func (c *CredentialHelper) Auth(ctx context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	if !isHTTP(endpoint) {
+		return nil, nil
+	}
+	gitBin := c.Git
+	if gitBin == "" {
+		gitBin = "git"
+	}
+
+	input := fmt.Sprintf("protocol=%s\nhost=%s\n", endpoint.Protocol, endpoint.Host)
+	if endpoint.Port != 0 {
+		input = fmt.Sprintf("protocol=%s\nhost=%s:%d\n", endpoint.Protocol, endpoint.Host, endpoint.Port)
+	}
+	if endpoint.Path != "" {
+		input += fmt.Sprintf("path=%s\n", strings.TrimPrefix(endpoint.Path, "/"))
+	}
+	if endpoint.User != "" {
+		input += fmt.Sprintf("username=%s\n", endpoint.User)
+	}
+
+	cmd := exec.CommandContext(ctx, gitBin, "credential", "fill")
+	cmd.Dir = c.Dir
+	cmd.Stdin = strings.NewReader(input + "\n")
+	// without helper credentials, git would ask on the terminal
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// git fails if no helper has credentials and prompting is disabled
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not run git credential fill: %w", err)
+	}
+
+	credentials := parseCredentials(stdout.String())
+	if credentials["password"] == "" {
+		return nil, nil
+	}
+	return &http.BasicAuth{Username: credentials["username"], Password: credentials["password"]}, nil
+}
+
+// parseCredentials returns the attributes of the output of git credential
+// fill.
+func parseCredentials(output string) map[string]string {
+	credentials := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok {
+			credentials[key] = value
+		}
+	}
+	return credentials
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestCredentialHelper_Auth(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// the helper only has credentials for gitlab.com
+	helper := `!f() { test "$1" = get || exit 0; while read line; do test "$line" = "host=gitlab.com" && found=1; done; test -n "$found" && echo username=oauth2 && echo password=secret; true; }; f`
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "credential.helper")
+	t.Setenv("GIT_CONFIG_VALUE_0", helper)
+
+	tests := []struct {
+		name string
+		url  string
+		want transport.AuthMethod
+	}{
+		{
+			name: "helper with credentials",
+			url:  "https://gitlab.com/group/project.git",
+			want: &http.BasicAuth{Username: "oauth2", Password: "secret"},
+		},
+		{
+			name: "helper without credentials",
+			url:  "https://github.com/leonsteinhaeuser/git-tag-bump.git",
+		},
+		{
+			name: "ssh remote",
+			url:  "git@gitlab.com:group/project.git",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&CredentialHelper{}).Auth(context.Background(), testEndpoint(t, tt.url))
+			if err != nil {
+				t.Fatalf("CredentialHelper.Auth() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CredentialHelper.Auth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseCredentials(t *testing.T) {
+	got := parseCredentials("protocol=https\nhost=gitlab.com\nusername=oauth2\npassword=se=cret\n")
+	want := map[string]string{"protocol": "https", "host": "gitlab.com", "username": "oauth2", "password": "se=cret"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCredentials() = %v, want %v", got, want)
+	}
+}
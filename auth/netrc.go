@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Netrc authenticates HTTP remotes with the login of the host in a netrc
+// file, as curl and git do.
+type Netrc struct {
+	// Path is the path of the netrc file. Defaults to $NETRC or ~/.netrc.
+	Path string
+}
+
+// netrcMachine is the login of a machine in a netrc file. The default entry
+// has an empty name.
+type netrcMachine struct {
+	Name     string
+	Login    string
+	Password string
+}
+
+// Auth returns the basic authentication of the host for HTTP remotes. If the
+// file does not exist or has no entry for the host, nil is returned.
+func (n *Netrc) Auth(_ context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	if !isHTTP(endpoint) {
+		return nil, nil
+	}
+	path, err := n.path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read netrc %q: %w", path, err)
+	}
+	machine := findMachine(parseNetrc(string(data)), endpoint.Host)
+	if machine == nil || machine.Password == "" {
+		return nil, nil
+	}
+	return &http.BasicAuth{Username: machine.Login, Password: machine.Password}, nil
+}
+
+// path returns the path of the netrc file.
+func (n *Netrc) path() (string, error) {
+	if n.Path != "" {
+		return n.Path, nil
+	}
+	if path := os.Getenv("NETRC"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".netrc"), nil
+}
+
+// parseNetrc returns the machines of the netrc content. Macro definitions
+// are skipped.
+func parseNetrc(content string) []netrcMachine {
+	machines := []netrcMachine{}
+	var current *netrcMachine
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			// every keyword except default is followed by a value
+			value := ""
+			if j+1 < len(fields) {
+				value = fields[j+1]
+			}
+			switch fields[j] {
+			case "machine":
+				machines = append(machines, netrcMachine{Name: value})
+				current = &machines[len(machines)-1]
+				j++
+			case "default":
+				machines = append(machines, netrcMachine{})
+				current = &machines[len(machines)-1]
+			case "login":
+				if current != nil {
+					current.Login = value
+				}
+				j++
+			case "password":
+				if current != nil {
+					current.Password = value
+				}
+				j++
+			case "account":
+				j++
+			case "macdef":
+				// a macro ends with an empty line
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	return machines
+}
+
+// findMachine returns the entry of the host, or the default entry. The
+// default entry only applies if it is the last entry of the file.
+func findMachine(machines []netrcMachine, host string) *netrcMachine {
+	for i := range machines {
+		if machines[i].Name == host {
+			return &machines[i]
+		}
+	}
+	if len(machines) > 0 && machines[len(machines)-1].Name == "" {
+		return &machines[len(machines)-1]
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func Test_parseNetrc(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []netrcMachine
+	}{
+		{
+			name:    "one line per machine",
+			content: "machine github.com login bot password secret\nmachine gitlab.com login oauth2 password token\n",
+			want: []netrcMachine{
+				{Name: "github.com", Login: "bot", Password: "secret"},
+				{Name: "gitlab.com", Login: "oauth2", Password: "token"},
+			},
+		},
+		{
+			name:    "one token per line with default",
+			content: "machine github.com\n  login bot\n  account ignored\n  password secret\n\ndefault login anonymous password guest\n",
+			want: []netrcMachine{
+				{Name: "github.com", Login: "bot", Password: "secret"},
+				{Login: "anonymous", Password: "guest"},
+			},
+		},
+		{
+			name:    "macro definition",
+			content: "macdef init\ncd /pub\nmachine evil.com login x password y\n\nmachine github.com login bot password secret\n",
+			want: []netrcMachine{
+				{Name: "github.com", Login: "bot", Password: "secret"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNetrc(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNetrc() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetrc_Auth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".netrc")
+	if err := os.WriteFile(path, []byte("machine gitea.example.com login ci password secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		netrc *Netrc
+		url   string
+		want  transport.AuthMethod
+	}{
+		{
+			name:  "machine of the host",
+			netrc: &Netrc{Path: path},
+			url:   "https://gitea.example.com/org/repo.git",
+			want:  &http.BasicAuth{Username: "ci", Password: "secret"},
+		},
+		{
+			name:  "other host",
+			netrc: &Netrc{Path: path},
+			url:   "https://github.com/leonsteinhaeuser/git-tag-bump.git",
+		},
+		{
+			name:  "ssh remote",
+			netrc: &Netrc{Path: path},
+			url:   "git@gitea.example.com:org/repo.git",
+		},
+		{
+			name:  "missing file",
+			netrc: &Netrc{Path: filepath.Join(t.TempDir(), ".netrc")},
+			url:   "https://gitea.example.com/org/repo.git",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.netrc.Auth(context.Background(), testEndpoint(t, tt.url))
+			if err != nil {
+				t.Fatalf("Netrc.Auth() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Netrc.Auth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// isSSH returns true if the endpoint is an SSH remote, including the scp-like
+// syntax user@host:path.
+func isSSH(endpoint *transport.Endpoint) bool {
+	return endpoint.Protocol == "ssh"
+}
+
+// sshUser returns the user of the endpoint or the default user "git".
+func sshUser(endpoint *transport.Endpoint) string {
+	if endpoint.User != "" {
+		return endpoint.User
+	}
+	return ssh.DefaultUsername
+}
+
+// SSHKey authenticates SSH remotes with a private key file. The host keys are
+// checked against the known_hosts files.
+type SSHKey struct {
+	// Path is the path of the private key file.
+	Path string
+	// Passphrase decrypts an encrypted private key.
+	Passphrase string
+}
+
+// Auth returns the public key authentication for SSH remotes.
+func (k *SSHKey) Auth(_ context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	if !isSSH(endpoint) || k.Path == "" {
+		return nil, nil
+	}
+	method, err := ssh.NewPublicKeysFromFile(sshUser(endpoint), k.Path, k.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ssh key %q: %w", k.Path, err)
+	}
+	return method, nil
+}
+
+// SSHAgent authenticates SSH remotes with the keys of the SSH agent listening
+// on SSH_AUTH_SOCK.
+type SSHAgent struct{}
+
+// Auth returns the agent authentication for SSH remotes. If no agent is
+// running, nil is returned.
+func (a *SSHAgent) Auth(_ context.Context, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	if !isSSH(endpoint) || os.Getenv("SSH_AUTH_SOCK") == "" {
+		return nil, nil
+	}
+	method, err := ssh.NewSSHAgentAuth(sshUser(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the ssh agent: %w", err)
+	}
+	return method, nil
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cssh "golang.org/x/crypto/ssh"
+)
+
+func TestSSHKey_Auth(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := cssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      *SSHKey
+		url      string
+		wantUser string
+		wantErr  bool
+	}{
+		{
+			name:     "scp-like url",
+			key:      &SSHKey{Path: path},
+			url:      "git@github.com:leonsteinhaeuser/git-tag-bump.git",
+			wantUser: "git",
+		},
+		{
+			name:     "user of the url",
+			key:      &SSHKey{Path: path},
+			url:      "ssh://deploy@git.example.com/project.git",
+			wantUser: "deploy",
+		},
+		{
+			name: "https remote",
+			key:  &SSHKey{Path: path},
+			url:  "https://github.com/leonsteinhaeuser/git-tag-bump.git",
+		},
+		{
+			name:    "missing key file",
+			key:     &SSHKey{Path: filepath.Join(t.TempDir(), "missing")},
+			url:     "git@github.com:leonsteinhaeuser/git-tag-bump.git",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.key.Auth(context.Background(), testEndpoint(t, tt.url))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SSHKey.Auth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantUser == "" {
+				if got != nil {
+					t.Errorf("SSHKey.Auth() = %v, want nil", got)
+				}
+				return
+			}
+			keys, ok := got.(*ssh.PublicKeys)
+			if !ok {
+				t.Fatalf("SSHKey.Auth() = %T, want *ssh.PublicKeys", got)
+			}
+			if keys.User != tt.wantUser {
+				t.Errorf("SSHKey.Auth() user = %v, want %v", keys.User, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestSSHAgent_Auth(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	got, err := (&SSHAgent{}).Auth(context.Background(), testEndpoint(t, "git@github.com:leonsteinhaeuser/git-tag-bump.git"))
+	if err != nil || got != nil {
+		t.Errorf("SSHAgent.Auth() = %v, %v, want nil without agent", got, err)
+	}
+}
@@ -1,12 +1,21 @@
 package branch
 
 import (
+	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	ErrComponentNotFound = fmt.Errorf("component not found")
+	ErrVersionLineFormat = fmt.Errorf("version line format is invalid")
+	ErrInvalidConfig     = fmt.Errorf("config is invalid")
+)
+
 // ReadConfig opens the config file at the given path.
 func ReadConfig(path string) (*Config, error) {
 	file, err := os.ReadFile(path)
@@ -18,6 +27,9 @@ func ReadConfig(path string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -25,6 +37,52 @@ type Config struct {
 	Major Identifier `yaml:"major"`
 	Minor Identifier `yaml:"minor"`
 	Patch Identifier `yaml:"patch"`
+
+	Components  []release.Component     `yaml:"components"`
+	Maintenance []MaintenanceIdentifier `yaml:"maintenance"`
+}
+
+// Validate returns an error wrapping ErrInvalidConfig if a regex of the
+// branch rules or the maintenance branches does not compile.
+func (c *Config) Validate() error {
+	rules := map[string]RegExIdentifier{
+		"major": c.Major.Branch.Name,
+		"minor": c.Minor.Branch.Name,
+		"patch": c.Patch.Branch.Name,
+	}
+	for i, maintenance := range c.Maintenance {
+		rules[fmt.Sprintf("maintenance[%d]", i)] = maintenance.Branch.Name
+	}
+	for name, rule := range rules {
+		if _, err := regexp.Compile(rule.RegEx); err != nil {
+			return fmt.Errorf("%w: regex of %s: %w", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+// Rule returns the regex of the identifier configured for the given bump type.
+func (c *Config) Rule(bumpType release.SemVerBumpType) string {
+	switch bumpType {
+	case release.SemVerBumpTypeMajor:
+		return c.Major.Branch.Name.RegEx
+	case release.SemVerBumpTypeMinor:
+		return c.Minor.Branch.Name.RegEx
+	case release.SemVerBumpTypePatch:
+		return c.Patch.Branch.Name.RegEx
+	}
+	return ""
+}
+
+// Component returns the component with the given name.
+// If no component with this name is configured, an error is returned.
+func (c *Config) Component(name string) (*release.Component, error) {
+	for i := range c.Components {
+		if c.Components[i].Name == name {
+			return &c.Components[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrComponentNotFound, name)
 }
 
 type Identifier struct {
@@ -35,6 +93,51 @@ func (i *Identifier) match(value string) bool {
 	return i.Branch.match(value)
 }
 
+// MaintenanceIdentifier maps maintenance branches to a version line.
+// The major and minor version of the line are taken from the named groups
+// "major" and "minor" of the regex, e.g. 'release/(?P<major>\d+)\.x'.
+// Major and Minor can be used instead for branches like 'release/1.4'.
+type MaintenanceIdentifier struct {
+	Branch BranchIdentifier `yaml:"branch"`
+	Major  *uint64          `yaml:"major"`
+	Minor  *uint64          `yaml:"minor"`
+}
+
+// line returns the version line of the given branch name.
+// If the branch name does not match, nil is returned.
+func (mi *MaintenanceIdentifier) line(value string) (*release.VersionLine, error) {
+	groups, ok := mi.Branch.Name.submatch(value)
+	if !ok {
+		return nil, nil
+	}
+	line := &release.VersionLine{}
+	switch {
+	case mi.Major != nil:
+		line.Major = *mi.Major
+	case groups["major"] != "":
+		major, err := strconv.ParseUint(groups["major"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrVersionLineFormat, err)
+		}
+		line.Major = major
+	default:
+		return nil, fmt.Errorf("%w: no major version for branch %q", ErrVersionLineFormat, value)
+	}
+	switch {
+	case mi.Minor != nil:
+		line.Minor = *mi.Minor
+		line.MinorFixed = true
+	case groups["minor"] != "":
+		minor, err := strconv.ParseUint(groups["minor"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrVersionLineFormat, err)
+		}
+		line.Minor = minor
+		line.MinorFixed = true
+	}
+	return line, nil
+}
+
 type BranchIdentifier struct {
 	Name RegExIdentifier `yaml:"name"`
 }
@@ -43,11 +146,45 @@ func (bi *BranchIdentifier) match(value string) bool {
 	return bi.Name.match(value)
 }
 
+// RegExIdentifier matches names by a regex. An empty regex is an unset rule
+// and matches no name.
 type RegExIdentifier struct {
 	RegEx string `yaml:"regex"`
 }
 
-// match returns true if the given name matches the regex.
+// match returns true if the given name matches the regex. An invalid regex,
+// which Config.Validate reports, matches no name.
 func (ri RegExIdentifier) match(value string) bool {
-	return regexp.MustCompile(ri.RegEx).MatchString(value)
+	if ri.RegEx == "" {
+		return false
+	}
+	re, err := regexp.Compile(ri.RegEx)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+// submatch returns the values of the named groups of the regex.
+// The second return value is false if the given name does not match, or if
+// the regex is empty or invalid.
+func (ri RegExIdentifier) submatch(value string) (map[string]string, bool) {
+	if ri.RegEx == "" {
+		return nil, false
+	}
+	re, err := regexp.Compile(ri.RegEx)
+	if err != nil {
+		return nil, false
+	}
+	match := re.FindStringSubmatch(value)
+	if match == nil {
+		return nil, false
+	}
+	groups := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+	return groups, true
 }
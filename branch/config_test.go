@@ -1,11 +1,20 @@
 package branch
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
 )
 
 func TestReadConfig(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(invalid, []byte("major:\n  branch:\n    name:\n      regex: '(['\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
 	type args struct {
 		path string
 	}
@@ -53,6 +62,14 @@ func TestReadConfig(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "invalid regex",
+			args: args{
+				path: invalid,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -205,6 +222,26 @@ func TestRegExIdentifier_match(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "empty regex",
+			fields: fields{
+				Regex: "",
+			},
+			args: args{
+				value: "docs/typo",
+			},
+			want: false,
+		},
+		{
+			name: "invalid regex",
+			fields: fields{
+				Regex: "([",
+			},
+			args: args{
+				value: "feat/x",
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -217,3 +254,190 @@ func TestRegExIdentifier_match(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr error
+	}{
+		{
+			name: "valid",
+			cfg: &Config{
+				Major: Identifier{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: "^feat!/"}}},
+				Maintenance: []MaintenanceIdentifier{
+					{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: `^release/(?P<major>\d+)\.x$`}}},
+				},
+			},
+		},
+		{
+			name: "invalid rule",
+			cfg: &Config{
+				Minor: Identifier{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: "(["}}},
+			},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name: "invalid maintenance branch",
+			cfg: &Config{
+				Maintenance: []MaintenanceIdentifier{
+					{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: `^release/(?P<major\d+)`}}},
+				},
+			},
+			wantErr: ErrInvalidConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Component(t *testing.T) {
+	cfg := &Config{
+		Components: []release.Component{
+			{Name: "api", TagPrefix: "services/api/", Paths: []string{"services/api"}},
+			{Name: "worker", TagPrefix: "services/worker/", Paths: []string{"services/worker"}},
+		},
+	}
+	tests := []struct {
+		name    string
+		arg     string
+		want    *release.Component
+		wantErr bool
+	}{
+		{
+			name:    "found",
+			arg:     "worker",
+			want:    &release.Component{Name: "worker", TagPrefix: "services/worker/", Paths: []string{"services/worker"}},
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			arg:     "web",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.Component(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.Component() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.Component() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceIdentifier_line(t *testing.T) {
+	one, four := uint64(1), uint64(4)
+	type fields struct {
+		RegEx string
+		Major *uint64
+		Minor *uint64
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		value   string
+		want    *release.VersionLine
+		wantErr bool
+	}{
+		{
+			name:   "major group",
+			fields: fields{RegEx: `^release/(?P<major>\d+)\.x$`},
+			value:  "release/1.x",
+			want:   &release.VersionLine{Major: 1},
+		},
+		{
+			name:   "major and minor group",
+			fields: fields{RegEx: `^release/(?P<major>\d+)\.(?P<minor>\d+)$`},
+			value:  "release/2.7",
+			want:   &release.VersionLine{Major: 2, Minor: 7, MinorFixed: true},
+		},
+		{
+			name:   "fixed major and minor",
+			fields: fields{RegEx: `^release/1\.4$`, Major: &one, Minor: &four},
+			value:  "release/1.4",
+			want:   &release.VersionLine{Major: 1, Minor: 4, MinorFixed: true},
+		},
+		{
+			name:   "not matching",
+			fields: fields{RegEx: `^release/(?P<major>\d+)\.x$`},
+			value:  "main",
+			want:   nil,
+		},
+		{
+			name:    "no major",
+			fields:  fields{RegEx: `^release/.*$`},
+			value:   "release/next",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mi := &MaintenanceIdentifier{
+				Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: tt.fields.RegEx}},
+				Major:  tt.fields.Major,
+				Minor:  tt.fields.Minor,
+			}
+			got, err := mi.line(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MaintenanceIdentifier.line() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MaintenanceIdentifier.line() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Rule(t *testing.T) {
+	cfg := &Config{
+		Major: Identifier{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: "^major/"}}},
+		Minor: Identifier{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: "^minor/"}}},
+		Patch: Identifier{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: "^patch/"}}},
+	}
+	tests := []struct {
+		name     string
+		bumpType release.SemVerBumpType
+		want     string
+	}{
+		{
+			name:     "major",
+			bumpType: release.SemVerBumpTypeMajor,
+			want:     "^major/",
+		},
+		{
+			name:     "minor",
+			bumpType: release.SemVerBumpTypeMinor,
+			want:     "^minor/",
+		},
+		{
+			name:     "patch",
+			bumpType: release.SemVerBumpTypePatch,
+			want:     "^patch/",
+		},
+		{
+			name:     "none",
+			bumpType: release.SemVerBumpTypeNone,
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Rule(tt.bumpType); got != tt.want {
+				t.Errorf("Config.Rule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
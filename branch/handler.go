@@ -8,7 +8,9 @@ import (
 )
 
 var (
-	ErrBranchNameFormat = fmt.Errorf("branch name format is invalid")
+	ErrNoMatchingRule = fmt.Errorf("no rule matches the branch")
+	// Deprecated: use ErrNoMatchingRule.
+	ErrBranchNameFormat = ErrNoMatchingRule
 )
 
 // branchName returns the name of the current branch
@@ -20,6 +22,11 @@ func branchName(repo *git.Repository) (string, error) {
 	return branch.Name().Short(), nil
 }
 
+// Current returns the name of the current branch.
+func Current(repo *git.Repository) (string, error) {
+	return branchName(repo)
+}
+
 // IdentifyBranch identifies the bump type of a branch
 // if the branch does not match any of the configured identifiers, an error is returned
 func IdentifyBranch(cfg *Config, branch string) (release.SemVerBumpType, error) {
@@ -33,7 +40,7 @@ func IdentifyBranch(cfg *Config, branch string) (release.SemVerBumpType, error)
 	if cfg.Patch.match(branch) {
 		return release.SemVerBumpTypePatch, nil
 	}
-	return "", fmt.Errorf("%w: for branch %q", ErrBranchNameFormat, branch)
+	return "", fmt.Errorf("%w: %q", ErrNoMatchingRule, branch)
 }
 
 // Identifier identifies the bump type of a branch or pull request.
@@ -42,12 +49,30 @@ func Identify(cfg *Config, repo *git.Repository) (release.SemVerBumpType, error)
 	if err != nil {
 		return "", err
 	}
-	bumpType, err := IdentifyBranch(cfg, bn)
-	if err == nil {
-		// we found a match for the branch name
-		return bumpType, nil
-	}
-	// we did not find a match for the branch name
 	// TODO: check if pull request was merged and has the correct labels
-	return "", fmt.Errorf("not implemented for branch %s", bn)
+	return IdentifyBranch(cfg, bn)
+}
+
+// IdentifyBranchLine identifies the version line of a maintenance branch.
+// If the branch does not match any of the configured maintenance identifiers, nil is returned.
+func IdentifyBranchLine(cfg *Config, branch string) (*release.VersionLine, error) {
+	for i := range cfg.Maintenance {
+		line, err := cfg.Maintenance[i].line(branch)
+		if err != nil {
+			return nil, err
+		}
+		if line != nil {
+			return line, nil
+		}
+	}
+	return nil, nil
+}
+
+// IdentifyLine identifies the version line of the current branch.
+func IdentifyLine(cfg *Config, repo *git.Repository) (*release.VersionLine, error) {
+	bn, err := branchName(repo)
+	if err != nil {
+		return nil, err
+	}
+	return IdentifyBranchLine(cfg, bn)
 }
@@ -1,6 +1,7 @@
 package branch
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -232,6 +233,9 @@ func Test_identifyBranch(t *testing.T) {
 				t.Errorf("identifyBranch() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil && !errors.Is(err, ErrNoMatchingRule) {
+				t.Errorf("identifyBranch() error = %v, want %v", err, ErrNoMatchingRule)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("identifyBranch() = %v, want %v", got, tt.want)
 			}
@@ -565,3 +569,46 @@ func TestIdentify(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentifyBranchLine(t *testing.T) {
+	lineCfg := &Config{
+		Maintenance: []MaintenanceIdentifier{
+			{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: `^release/(?P<major>\d+)\.x$`}}},
+			{Branch: BranchIdentifier{Name: RegExIdentifier{RegEx: `^release/(?P<major>\d+)\.(?P<minor>\d+)$`}}},
+		},
+	}
+	tests := []struct {
+		name    string
+		branch  string
+		want    *release.VersionLine
+		wantErr bool
+	}{
+		{
+			name:   "major line",
+			branch: "release/1.x",
+			want:   &release.VersionLine{Major: 1},
+		},
+		{
+			name:   "minor line",
+			branch: "release/1.4",
+			want:   &release.VersionLine{Major: 1, Minor: 4, MinorFixed: true},
+		},
+		{
+			name:   "no maintenance branch",
+			branch: "main",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IdentifyBranchLine(lineCfg, tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IdentifyBranchLine() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IdentifyBranchLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1241 @@
+package bump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/leonsteinhaeuser/git-tag-bump/auth"
+	"github.com/leonsteinhaeuser/git-tag-bump/branch"
+	"github.com/leonsteinhaeuser/git-tag-bump/changelog"
+	"github.com/leonsteinhaeuser/git-tag-bump/commit"
+	"github.com/leonsteinhaeuser/git-tag-bump/gomod"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+	"github.com/leonsteinhaeuser/git-tag-bump/sign"
+)
+
+var (
+	ErrInvalidOptions = fmt.Errorf("options are invalid")
+)
+
+type GoModuleCheck string
+
+const (
+	// GoModuleCheckWarn logs a warning if the module path of go.mod does not
+	// match the major version of the new tag.
+	GoModuleCheckWarn GoModuleCheck = "warn"
+	// GoModuleCheckError fails if the module path of go.mod does not match
+	// the major version of the new tag.
+	GoModuleCheckError GoModuleCheck = "error"
+)
+
+func (g GoModuleCheck) String() string {
+	return string(g)
+}
+
+type ShallowMode string
+
+const (
+	// ShallowFail fails with release.ErrShallow if the repository is a
+	// shallow clone, as the latest tag may not be found in its history.
+	ShallowFail ShallowMode = "fail"
+	// ShallowDeepen fetches the full history of a shallow clone.
+	ShallowDeepen ShallowMode = "deepen"
+)
+
+func (s ShallowMode) String() string {
+	return string(s)
+}
+
+// Options configures a Bumper. DefaultOptions returns the defaults of the CLI.
+type Options struct {
+	// RepoPath is the path of the repository. It is ignored if Repository or
+	// RepoURL is set.
+	RepoPath string
+	// RepoURL is the URL of a remote repository that is read into memory
+	// instead of a local checkout. Only its references are listed, unless the
+	// options analyze commits or create a tag, which clones it. It is ignored
+	// if Repository is set.
+	RepoURL string
+	// Repository is an already opened repository.
+	Repository *git.Repository
+	// Config holds the branch rules, components and maintenance branches.
+	// Unset branch rules match no branch.
+	Config *branch.Config
+
+	// Scheme is the version scheme. By default, the SemVer scheme is used.
+	Scheme release.Scheme
+	// Component is the name of a component of the config. It scopes the tags
+	// and the commits to the component.
+	Component string
+	// TagPrefix is the prefix of the tags to consider and create, e.g.
+	// "services/api/". It is ignored if Component is set.
+	TagPrefix string
+	// ReachableFrom only considers tags reachable from the given revision,
+	// similar to git describe. If empty, all tags are considered.
+	ReachableFrom string
+	// Target is the revision that is analyzed and tagged instead of HEAD, a
+	// commit hash, branch or tag. A branch also replaces the current branch
+	// for the branch rules, unless BranchName is set.
+	Target string
+	// BaseTag overrides the latest tag, if it has another version core.
+	BaseTag string
+	// FetchTags fetches the tags of the primary remote before the latest tag
+	// is determined, as CI checkouts often lack tags.
+	FetchTags bool
+	// Shallow handles shallow clones if FetchTags is set. Defaults to
+	// ShallowFail.
+	Shallow ShallowMode
+
+	// BumpType is the bump type used if it is not determined by BranchName,
+	// ConventionalCommits or AutoBump. Defaults to patch.
+	BumpType release.SemVerBumpType
+	// BranchName determines the bump type by the branch rules of the config.
+	BranchName string
+	// ConventionalCommits determines the bump type by the Conventional
+	// Commit messages since the latest tag.
+	ConventionalCommits bool
+	// AutoBump determines the bump type by the current branch and the branch
+	// rules of the config.
+	AutoBump bool
+
+	PreRelease       bool
+	PreReleaseFormat release.PreReleaseFormat
+	PreReleasePrefix string
+	// PreReleaseAware only increments the pre-release counter if the pending
+	// pre-release already covers the requested bump.
+	PreReleaseAware bool
+	// Channels is the ordered list of allowed pre-release channels.
+	Channels release.Channels
+
+	// MetadataFormat adds build metadata to the version. If empty, no
+	// metadata is added.
+	MetadataFormat release.MetadataFormat
+	// BuildNumber is used by the build metadata format.
+	BuildNumber string
+	// StripTagMetadata removes the build metadata from the tag name, as many
+	// registries reject the '+' character. The result keeps the metadata.
+	StripTagMetadata bool
+	// VPrefix prefixes the tag with a 'v', e.g. v1.0.0 instead of 1.0.0.
+	VPrefix bool
+
+	// Promote promotes the latest pre-release, or BaseTag, to a stable release.
+	Promote bool
+	// PromoteCommit tags the commit of the promoted pre-release instead of HEAD.
+	PromoteCommit bool
+
+	// GoModule checks the module path of go.mod against the major version of
+	// the new tag. If empty, go.mod is not read.
+	GoModule GoModuleCheck
+	// GoModuleDir is the directory of the Go module relative to the
+	// repository root. Defaults to the first path of the component or the
+	// repository root.
+	GoModuleDir string
+	// GoModuleTags prefixes the tags with the directory of the Go module.
+	GoModuleTags bool
+
+	// Create creates the tag and pushes it to the remote.
+	Create bool
+	// ForceTag creates a new tag even if the target commit already has a tag
+	// of the version scheme and tag prefix. Otherwise, the existing tag is
+	// returned, so a re-run does not tag the commit twice.
+	ForceTag bool
+	// PushExisting pushes the existing tag of the target commit to the
+	// remotes again if Create is set, e.g. after the push of a previous run
+	// failed.
+	PushExisting bool
+	// Lightweight creates a lightweight instead of an annotated tag.
+	Lightweight bool
+	// Tagger is the signature of annotated tags.
+	Tagger *object.Signature
+	// Signer signs the annotated tag. The signature is verified before the
+	// tag is pushed. If nil, the tag is not signed.
+	Signer sign.Signer
+	// TagMessageTemplate is the text/template of the annotated tag message,
+	// see changelog.Message. If empty, the message is the tag name.
+	TagMessageTemplate string
+	// Auth authenticates the push. It takes precedence over AuthProvider.
+	Auth transport.AuthMethod
+	// AuthProvider resolves the authentication of the push by the URL of the
+	// remote. If neither Auth nor AuthProvider is set, the push is not
+	// authenticated.
+	AuthProvider auth.Provider
+	// Remotes are the names or URLs of the remotes the tag is pushed to. The
+	// first one is the primary remote. Defaults to origin.
+	Remotes []string
+	// Atomic deletes the tag from all remotes and the repository again if
+	// the push to any remote fails. Otherwise, only a failed push to the
+	// primary remote fails the run, while failed pushes to the other remotes
+	// are reported in the result.
+	Atomic bool
+	// Retries is the number of times the version is recomputed if another
+	// job pushed the tag for another commit first.
+	Retries int
+	// Progress receives the progress of the push. If nil, it is discarded.
+	Progress io.Writer
+
+	// Changelog is the path of the changelog file relative to the repository
+	// root, e.g. CHANGELOG.md. If set, a section with the commits since the
+	// previous version is prepended to it. The file is not committed.
+	Changelog string
+	// ChangelogTemplate is the text/template of the changelog section.
+	// Defaults to changelog.DefaultTemplate.
+	ChangelogTemplate string
+
+	// Explain records the decision trace in the result. It implies a dry
+	// run, so no tag is fetched, created or pushed.
+	Explain bool
+	// Logger receives the diagnostics. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// DefaultOptions returns the options with the defaults of the CLI.
+func DefaultOptions() Options {
+	return Options{
+		RepoPath:         ".",
+		Config:           &branch.Config{},
+		Scheme:           release.SemVer{},
+		BumpType:         release.SemVerBumpTypePatch,
+		PreReleaseFormat: release.PreReleaseFormatSemVer,
+		PreReleasePrefix: "rc",
+		VPrefix:          true,
+		Retries:          3,
+	}
+}
+
+// Bumper computes the next version of a repository and optionally creates
+// and pushes its tag.
+type Bumper struct {
+	opts      Options
+	repo      *git.Repository
+	component *release.Component
+	trace     *release.Trace
+	log       *slog.Logger
+	// target is the resolved commit of Target and targetBranch its branch.
+	// If target is the zero hash, HEAD is used.
+	target       plumbing.Hash
+	targetBranch string
+}
+
+// New validates the options and opens the repository.
+func New(opts Options) (*Bumper, error) {
+	if opts.Config == nil {
+		opts.Config = &branch.Config{}
+	}
+	if err := opts.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	if opts.Scheme == nil {
+		opts.Scheme = release.SemVer{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.Create && !opts.Lightweight && opts.Tagger == nil {
+		return nil, fmt.Errorf("%w: annotated tags require a tagger", ErrInvalidOptions)
+	}
+	if opts.Signer != nil && opts.Lightweight {
+		return nil, fmt.Errorf("%w: lightweight tags cannot be signed", ErrInvalidOptions)
+	}
+	// an unknown bump type would keep the version and report that no release is needed
+	switch opts.BumpType {
+	case "":
+		opts.BumpType = release.SemVerBumpTypePatch
+	case release.SemVerBumpTypeMajor, release.SemVerBumpTypeMinor, release.SemVerBumpTypePatch, release.SemVerBumpTypeNone:
+	default:
+		return nil, fmt.Errorf("%w: unknown bump type %q", ErrInvalidOptions, opts.BumpType)
+	}
+	switch opts.GoModule {
+	case "", GoModuleCheckWarn, GoModuleCheckError:
+	default:
+		return nil, fmt.Errorf("%w: unknown go module check %q", ErrInvalidOptions, opts.GoModule)
+	}
+	switch opts.Shallow {
+	case "":
+		opts.Shallow = ShallowFail
+	case ShallowFail, ShallowDeepen:
+	default:
+		return nil, fmt.Errorf("%w: unknown shallow mode %q", ErrInvalidOptions, opts.Shallow)
+	}
+
+	if opts.Repository == nil && opts.RepoURL != "" && opts.Changelog != "" {
+		return nil, fmt.Errorf("%w: the changelog can not be written without a local checkout", ErrInvalidOptions)
+	}
+
+	// a remote repository is read into memory by Run
+	repo := opts.Repository
+	if repo == nil && opts.RepoURL == "" {
+		var err error
+		repo, err = git.PlainOpen(opts.RepoPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not open repository %q: %w", opts.RepoPath, err)
+		}
+	}
+
+	// the component scopes the tag discovery, the commit analysis and the created tag
+	component := &release.Component{TagPrefix: opts.TagPrefix}
+	if opts.Component != "" {
+		configured, err := opts.Config.Component(opts.Component)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+		copied := *configured
+		component = &copied
+	}
+
+	b := &Bumper{
+		opts:      opts,
+		repo:      repo,
+		component: component,
+		log:       opts.Logger,
+	}
+	if opts.Explain {
+		b.trace = &release.Trace{}
+	}
+	if opts.Create && repo != nil {
+		for _, remote := range b.remotes() {
+			if _, err := release.Remote(repo, remote); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+			}
+		}
+	}
+	// nested go modules are tagged with their directory as prefix
+	if opts.GoModuleTags {
+		component.TagPrefix = gomod.TagPrefix(b.goModuleDirectory())
+	}
+	return b, nil
+}
+
+// Run computes the new version and creates and pushes its tag if Create is
+// set. If no release is needed, the result describes the latest tag and
+// release.ErrNoReleaseNeeded is returned.
+func (b *Bumper) Run(ctx context.Context) (*release.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if b.repo == nil {
+		if err := b.openRemote(ctx); err != nil {
+			return nil, err
+		}
+	}
+	scheme := b.opts.Scheme
+	b.trace.Addf("using the version scheme %s", scheme)
+	if b.component.Name != "" {
+		b.trace.Addf("using the component %q with the paths %q", b.component.Name, b.component.Paths)
+	}
+	if b.component.TagPrefix != "" {
+		b.trace.Addf("using the tag prefix %q", b.component.TagPrefix)
+	}
+	if b.opts.FetchTags {
+		if err := b.fetchTags(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.resolveTarget(); err != nil {
+		return nil, err
+	}
+
+	// concurrent jobs may compute the same version, the one that pushes first wins
+	for attempt := 1; ; attempt++ {
+		result, err := b.next(ctx)
+		// a conflict of an existing tag does not go away by recomputing
+		if !errors.Is(err, release.ErrTagConflict) || result.Existing || attempt > b.opts.Retries {
+			return result, err
+		}
+		b.log.Warn("the tag was pushed by another job, recomputing the version", "tag", result.Tag, "attempt", attempt, "error", err)
+		if err := b.refreshTags(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// next computes the next version and creates and pushes its tag if Create is
+// set.
+func (b *Bumper) next(ctx context.Context) (*release.Result, error) {
+	scheme := b.opts.Scheme
+	tagOptions := []release.TagOption{release.WithTagPrefix(b.component.TagPrefix), release.WithScheme(scheme), release.WithTrace(b.trace), release.WithLogger(b.log)}
+	if b.opts.ReachableFrom != "" {
+		hash, err := b.repo.ResolveRevision(plumbing.Revision(b.opts.ReachableFrom))
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve revision %q: %w", b.opts.ReachableFrom, err)
+		}
+		b.trace.Addf("only considering tags reachable from %q (%s)", b.opts.ReachableFrom, hash)
+		tagOptions = append(tagOptions, release.WithReachableFrom(*hash))
+	}
+
+	// maintenance branches only consider and create tags of their version line
+	line, err := b.versionLine()
+	if err != nil {
+		return nil, err
+	}
+	if line != nil {
+		b.trace.Addf("the branch is a maintenance branch of the version line %s", line)
+	}
+	tagOptions = append(tagOptions, release.WithVersionLine(line))
+
+	// the promoted commit is only known after the pre-release was found
+	if !b.opts.Promote || !b.opts.PromoteCommit {
+		if result, err := b.existingTag(ctx, line, plumbing.ZeroHash); result != nil || err != nil {
+			return result, err
+		}
+	}
+
+	if b.opts.Promote {
+		return b.promote(ctx, tagOptions, line)
+	}
+
+	latest, err := release.GetLatestSemVerTagFromRepo(b.repo, b.opts.PreRelease, tagOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	// override the current latest identified tag with the base tag
+	if b.opts.BaseTag != "" {
+		overrideTag, err := b.baseTag()
+		if err != nil {
+			return nil, err
+		}
+		if overrideTag.Major() != latest.Major() || overrideTag.Minor() != latest.Minor() || overrideTag.Patch() != latest.Patch() {
+			// if the major, minor or patch version of the override tag does not match the latest tag, use the override tag
+			b.trace.Addf("the base tag %q overrides the latest tag %q", b.opts.BaseTag, latest.Original())
+			latest = overrideTag
+		} else {
+			b.trace.Addf("the base tag %q is ignored: it has the same major, minor and patch version as the latest tag %q", b.opts.BaseTag, latest.Original())
+		}
+	}
+
+	// a component without changes since its latest tag does not get a new version
+	if len(b.component.Paths) > 0 {
+		commits, err := b.commitsSince(latest, plumbing.ZeroHash)
+		if err != nil {
+			return nil, err
+		}
+		if len(commits) == 0 {
+			latestTag := b.component.TagName(latest.Original())
+			// without a tag, there is no latest version to report
+			if _, err := release.ResolveTag(b.repo, latestTag); errors.Is(err, git.ErrTagNotFound) {
+				b.trace.Addf("no commit changed the paths of the component and it has no tag %q", latestTag)
+				return nil, fmt.Errorf("%w: the component has no tag and no commit changed its paths", release.ErrNoTags)
+			}
+			reason := fmt.Sprintf("no commit changed the paths of the component since %q", latestTag)
+			return b.noRelease(latest, reason)
+		}
+	}
+
+	bt, reason, err := b.bumpType(latest)
+	if err != nil {
+		return nil, err
+	}
+	b.trace.Addf("bump type %s: %s", bt, reason)
+
+	metadata, err := b.buildMetadata(plumbing.ZeroHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bumpOptions := release.BumpOptions{
+		Type:             bt,
+		PreRelease:       b.opts.PreRelease,
+		PreReleaseFormat: b.opts.PreReleaseFormat,
+		PreReleasePrefix: b.opts.PreReleasePrefix,
+		Channels:         b.opts.Channels,
+		Metadata:         metadata,
+		Scheme:           scheme,
+		Logger:           b.log,
+	}
+	b.trace.Addf("bumping %q with type=%s pre-release=%t pre-release-format=%s pre-release-prefix=%q pre-release-channels=%q metadata=%q",
+		latest.Original(), bt, b.opts.PreRelease, b.opts.PreReleaseFormat, b.opts.PreReleasePrefix, strings.Join(b.opts.Channels, ","), metadata)
+	var newVersion *semver.Version
+	if b.opts.PreRelease && b.opts.PreReleaseAware {
+		// compare the pending pre-release with the latest release to avoid a double bump
+		latestRelease, err := release.GetLatestSemVerTagFromRepo(b.repo, false, tagOptions...)
+		if err != nil {
+			return nil, err
+		}
+		b.trace.Addf("comparing the pending pre-release %q with the latest release %q", latest.Original(), latestRelease.Original())
+		newVersion, err = release.BumpPendingPreRelease(latestRelease, latest, bumpOptions)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		newVersion, err = release.Bump(latest, bumpOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a version that is not newer than the latest one, e.g. bump type none, is no release
+	if !newVersion.GreaterThan(latest) {
+		return b.noRelease(latest, fmt.Sprintf("bump type %s keeps the version %q", bt, latest.Original()))
+	}
+
+	// a bump must not leave the version line of a maintenance branch
+	if line != nil {
+		if err := line.Check(newVersion); err != nil {
+			return nil, fmt.Errorf("could not bump %s of %s: %w", bt, latest.Original(), err)
+		}
+	}
+
+	result := &release.Result{
+		PreviousTag: b.component.TagName(latest.Original()),
+		BumpType:    bt,
+		Reason:      reason,
+	}
+	return result, b.publish(ctx, result, newVersion, latest, plumbing.ZeroHash)
+}
+
+// openRemote reads the repository of RepoURL into memory. It is cloned if the
+// options need its commits, otherwise only its references are listed.
+func (b *Bumper) openRemote(ctx context.Context) error {
+	// public remotes can be read without credentials
+	authMethod, err := b.authURL(ctx, b.opts.RepoURL)
+	if err != nil && !errors.Is(err, release.ErrAuthMissing) {
+		return err
+	}
+	if b.needsHistory() {
+		b.log.Info("cloning the repository into memory", "url", b.opts.RepoURL)
+		b.repo, err = release.CloneRemote(ctx, b.opts.RepoURL, authMethod, b.opts.Progress)
+		if err != nil {
+			return err
+		}
+		b.trace.Addf("cloned %q into memory to analyze its commits", b.opts.RepoURL)
+		return nil
+	}
+	b.repo, err = release.ListRemote(ctx, b.opts.RepoURL, authMethod)
+	if err != nil {
+		return err
+	}
+	b.trace.Addf("listed the references of %q", b.opts.RepoURL)
+	return nil
+}
+
+// needsHistory returns true if the options analyze commits or create a tag,
+// which needs the objects of the repository and not only its references.
+func (b *Bumper) needsHistory() bool {
+	o := b.opts
+	return o.Create || o.ConventionalCommits || o.AutoBump || o.ReachableFrom != "" || o.GoModule != "" ||
+		o.Target != "" || len(b.component.Paths) > 0 || (len(o.Config.Maintenance) > 0 && o.BranchName == "")
+}
+
+// fetchTags fetches the tags of the primary remote. A shallow clone is
+// deepened or fails by the shallow mode of the options. A dry run of Explain
+// leaves the repository untouched, so nothing is fetched.
+func (b *Bumper) fetchTags(ctx context.Context) error {
+	remote := b.remotes()[0]
+	if b.opts.Explain {
+		b.trace.Addf("dry run: the tags of %q are not fetched", remote)
+		return nil
+	}
+	shallow, err := release.IsShallow(b.repo)
+	if err != nil {
+		return err
+	}
+	if shallow {
+		if b.opts.Shallow != ShallowDeepen {
+			return fmt.Errorf("%w: the latest tag may be missing from its history, fetch the full history or deepen the clone", release.ErrShallow)
+		}
+		b.trace.Addf("the repository is a shallow clone, fetching its full history from %q", remote)
+		b.log.Info("deepening the shallow clone", "remote", remote)
+	}
+
+	// public remotes can be read without credentials
+	authMethod, err := b.auth(ctx, remote)
+	if err != nil && !errors.Is(err, release.ErrAuthMissing) {
+		return err
+	}
+	if err := release.FetchTags(ctx, b.repo, remote, shallow, authMethod, b.opts.Progress); err != nil {
+		return err
+	}
+	b.trace.Addf("fetched the tags of %q", remote)
+	b.log.Info("fetched tags", "remote", remote)
+	return nil
+}
+
+// bumpType returns the bump type and the reason why it was chosen.
+func (b *Bumper) bumpType(latest *semver.Version) (release.SemVerBumpType, string, error) {
+	cfg := b.opts.Config
+	switch {
+	case b.opts.BranchName != "":
+		bt, err := branch.IdentifyBranch(cfg, b.opts.BranchName)
+		if err != nil {
+			return "", "", err
+		}
+		return bt, fmt.Sprintf("the branch %q matches the rule %q", b.opts.BranchName, cfg.Rule(bt)), nil
+	case b.opts.ConventionalCommits:
+		commits, err := b.commitsSince(latest, plumbing.ZeroHash)
+		if err != nil {
+			return "", "", err
+		}
+		return commit.Identify(commits), fmt.Sprintf("highest bump of the %d conventional commits since %q", len(commits), b.component.TagName(latest.Original())), nil
+	case b.opts.AutoBump:
+		current, err := b.branchName()
+		if err != nil {
+			return "", "", err
+		}
+		bt, err := branch.IdentifyBranch(cfg, current)
+		if err != nil {
+			return "", "", err
+		}
+		if b.targetBranch != "" {
+			return bt, fmt.Sprintf("the branch %q of the target matches the rule %q", current, cfg.Rule(bt)), nil
+		}
+		return bt, fmt.Sprintf("the current branch %q matches the rule %q", current, cfg.Rule(bt)), nil
+	}
+	return b.opts.BumpType, "set by the options", nil
+}
+
+// baseTag parses the base tag of the options.
+func (b *Bumper) baseTag() (*semver.Version, error) {
+	version, err := semver.NewVersion(b.opts.BaseTag)
+	if err != nil {
+		return nil, fmt.Errorf("%w: base tag %q is not a version: %w", ErrInvalidOptions, b.opts.BaseTag, err)
+	}
+	return version, nil
+}
+
+// noRelease returns the latest version as result together with
+// release.ErrNoReleaseNeeded and the given reason.
+func (b *Bumper) noRelease(latest *semver.Version, reason string) (*release.Result, error) {
+	latestTag := b.component.TagName(latest.Original())
+	result := &release.Result{
+		PreviousTag: latestTag,
+		Tag:         latestTag,
+		BumpType:    release.SemVerBumpTypeNone,
+		Reason:      reason,
+	}
+	result.SetVersion(b.opts.Scheme, latest)
+	if hash, err := release.ResolveTag(b.repo, latestTag); err == nil {
+		result.Commit = hash.String()
+	}
+	b.trace.Addf("%s: no new version", reason)
+	result.Explain = b.explain()
+	return result, fmt.Errorf("%w: %s", release.ErrNoReleaseNeeded, reason)
+}
+
+// existingTag returns the result of the tag of the version scheme that
+// already points to the target commit, and pushes it again if PushExisting is
+// set. Pre-releases are only returned for pre-releases. If the commit has no
+// such tag or ForceTag is set, nil is returned. If target is the zero hash,
+// the target of the options or HEAD is checked.
+func (b *Bumper) existingTag(ctx context.Context, line *release.VersionLine, target plumbing.Hash) (*release.Result, error) {
+	if b.opts.ForceTag {
+		b.trace.Addf("not checking for an existing tag: a new tag is forced")
+		return nil, nil
+	}
+	if target.IsZero() {
+		head, err := b.head()
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			// a repository without commits has no tags
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		target = head
+	}
+
+	preRelease := b.opts.PreRelease && !b.opts.Promote
+	existing, err := release.GetLatestSemVerTagFromRepo(b.repo, preRelease,
+		release.WithTagPrefix(b.component.TagPrefix), release.WithScheme(b.opts.Scheme), release.WithVersionLine(line),
+		release.WithPointsAt(target), release.WithRequiredTag())
+	if errors.Is(err, release.ErrNoTags) {
+		b.trace.Addf("the commit %s has no tag yet", target)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tag := b.component.TagName(existing.Original())
+	result := &release.Result{
+		PreviousTag: tag,
+		Tag:         tag,
+		BumpType:    release.SemVerBumpTypeNone,
+		Reason:      fmt.Sprintf("the commit %s already has the tag %q", target, tag),
+		Commit:      target.String(),
+		Existing:    true,
+	}
+	result.SetVersion(b.opts.Scheme, existing)
+	b.trace.Addf("%s: returning the existing tag", result.Reason)
+	b.log.Info("the commit already has a tag", "tag", tag, "commit", target.String())
+
+	if b.opts.Create && b.opts.PushExisting {
+		if err := b.pushExisting(ctx, result); err != nil {
+			return result, err
+		}
+	}
+	result.Explain = b.explain()
+	return result, nil
+}
+
+// pushExisting pushes the existing tag of the result to the remotes again.
+func (b *Bumper) pushExisting(ctx context.Context, result *release.Result) error {
+	remotes := b.remotes()
+	if b.opts.Explain {
+		b.trace.Addf("dry run: the existing tag %q is not pushed to %q", result.Tag, strings.Join(remotes, ","))
+		return nil
+	}
+	auths, err := b.auths(ctx, remotes)
+	if err != nil {
+		return err
+	}
+	ref, err := b.repo.Tag(result.Tag)
+	if err != nil {
+		return err
+	}
+	return b.push(ctx, result, ref, remotes, auths)
+}
+
+// promote publishes the latest pre-release, or the base tag, as stable release.
+func (b *Bumper) promote(ctx context.Context, tagOptions []release.TagOption, line *release.VersionLine) (*release.Result, error) {
+	var preRelease *semver.Version
+	var err error
+	if b.opts.BaseTag != "" {
+		preRelease, err = b.baseTag()
+	} else {
+		required := append(slices.Clip(tagOptions), release.WithRequiredTag())
+		preRelease, err = release.GetLatestSemVerTagFromRepo(b.repo, true, required...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := release.Promote(preRelease)
+	if err != nil {
+		return nil, err
+	}
+	result := &release.Result{
+		PreviousTag: b.component.TagName(preRelease.Original()),
+		BumpType:    release.SemVerBumpTypeNone,
+		Reason:      fmt.Sprintf("promotion of the pre-release %q", preRelease.Original()),
+	}
+	b.trace.Addf("promoting the pre-release %q to %s", preRelease.Original(), version)
+
+	target := plumbing.ZeroHash
+	if b.opts.PromoteCommit {
+		preReleaseTag := b.component.TagName(preRelease.Original())
+		target, err = release.ResolveTag(b.repo, preReleaseTag)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve tag %q: %w", preReleaseTag, err)
+		}
+		b.trace.Addf("tagging the commit %s of the pre-release %q", target, preReleaseTag)
+		if result, err := b.existingTag(ctx, line, target); result != nil || err != nil {
+			return result, err
+		}
+	}
+
+	metadata, err := b.buildMetadata(target)
+	if err != nil {
+		return nil, err
+	}
+	withMetadata, err := version.SetMetadata(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", release.ErrInvalidMetadata, err)
+	}
+
+	// the changelog of a stable release covers all of its pre-releases
+	latestRelease, err := release.GetLatestSemVerTagFromRepo(b.repo, false, tagOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return result, b.publish(ctx, result, &withMetadata, latestRelease, target)
+}
+
+// publish completes the result with the version and the tag of the
+// component, writes the changelog of the commits since the previous version
+// and creates and pushes the tag for the target commit if Create is set.
+// If target is the zero hash, the target of the options or HEAD is tagged.
+func (b *Bumper) publish(ctx context.Context, result *release.Result, version, previous *semver.Version, target plumbing.Hash) error {
+	scheme := b.opts.Scheme
+	newTag := scheme.Format(version)
+	// many registries reject the '+' of the build metadata in tag names
+	if b.opts.StripTagMetadata {
+		withoutMetadata, _ := version.SetMetadata("")
+		newTag = scheme.Format(&withoutMetadata)
+	}
+
+	// the module path must declare the major version of the tag, otherwise go get can not use it
+	if b.opts.GoModule != "" {
+		err := b.checkGoModule(version, target)
+		switch {
+		case err != nil && b.opts.GoModule == GoModuleCheckWarn:
+			b.log.Warn("go module does not match the new tag", "error", err)
+		case err != nil:
+			return err
+		}
+	}
+
+	// add v prefix if enabled
+	if b.opts.VPrefix {
+		newTag = fmt.Sprintf("v%s", newTag)
+	}
+	newTag = b.component.TagName(newTag)
+	b.trace.Addf("new tag %q", newTag)
+
+	if target.IsZero() {
+		head, err := b.head()
+		if err != nil {
+			return err
+		}
+		target = head
+	}
+	result.Tag = newTag
+	result.Commit = target.String()
+	result.SetVersion(scheme, version)
+
+	// the tag is created first, so a retry does not write the changelog twice
+	if b.opts.Create {
+		if err := b.createTag(ctx, result, previous, target); err != nil {
+			return err
+		}
+	}
+
+	if b.opts.Changelog != "" {
+		if err := b.writeChangelog(result, previous, target); err != nil {
+			return err
+		}
+	}
+	result.Explain = b.explain()
+	return nil
+}
+
+// tagMessage returns the message of an annotated tag. It is rendered by the
+// tag message template with the commits between the previous version and the
+// target commit. Without template, the message is the tag name.
+func (b *Bumper) tagMessage(result *release.Result, previous *semver.Version, target plumbing.Hash) (string, error) {
+	if b.opts.Lightweight || b.opts.TagMessageTemplate == "" {
+		return result.Tag, nil
+	}
+	commits, err := b.commitsSince(previous, target)
+	if err != nil {
+		return "", err
+	}
+	return changelog.RenderMessage(b.opts.TagMessageTemplate, changelog.NewMessage(result, commits))
+}
+
+// createTag creates the tag of the result for the target commit and pushes
+// it to the remotes. In explain mode, only the tag message is rendered.
+func (b *Bumper) createTag(ctx context.Context, result *release.Result, previous *semver.Version, target plumbing.Hash) error {
+	message, err := b.tagMessage(result, previous, target)
+	if err != nil {
+		return err
+	}
+	remotes := b.remotes()
+	if b.opts.Explain {
+		b.trace.Addf("dry run: the tag %q is neither created nor pushed to %q", result.Tag, strings.Join(remotes, ","))
+		if !b.opts.Lightweight {
+			b.trace.Addf("tag message %q", message)
+		}
+		if b.opts.Signer != nil {
+			b.trace.Addf("the tag would be signed with the %s key", b.opts.Signer.Format())
+		}
+		return nil
+	}
+
+	// the credentials are resolved first, so no tag is left behind without them
+	auths, err := b.auths(ctx, remotes)
+	if err != nil {
+		return err
+	}
+
+	// another job may have pushed the tag since the tags were read
+	if err := b.checkRemoteTag(ctx, remotes[0], result.Tag, target, auths[0]); err != nil {
+		return err
+	}
+
+	ref, err := b.createTagRef(result.Tag, target, message)
+	if err != nil {
+		return err
+	}
+	result.Created = true
+	b.log.Info("created tag", "tag", result.Tag, "commit", target.String())
+	return b.push(ctx, result, ref, remotes, auths)
+}
+
+// push pushes the tag to the remotes in order and records the result of
+// each remote. A failed push to the primary remote stops the push. In atomic
+// mode, a failed push to any remote rolls back the created tag.
+func (b *Bumper) push(ctx context.Context, result *release.Result, ref *plumbing.Reference, remotes []string, auths []transport.AuthMethod) error {
+	for i, remote := range remotes {
+		err := release.PushTag(ctx, b.repo, remote, ref, auths[i], b.opts.Progress)
+		// the primary remote decides whether another job took the tag
+		if i == 0 {
+			err = b.verifyRemoteTag(ctx, remote, ref, auths[i], err)
+		}
+		remoteResult := release.RemoteResult{Remote: remote, Pushed: err == nil}
+		if err != nil {
+			remoteResult.Error = err.Error()
+		}
+		result.Remotes = append(result.Remotes, remoteResult)
+		result.Pushed = result.Remotes[0].Pushed
+
+		switch {
+		case err == nil:
+			b.log.Info("pushed tag", "tag", result.Tag, "remote", remote)
+		case errors.Is(err, release.ErrTagConflict) && result.Created:
+			b.deleteTag(result)
+			return err
+		case b.opts.Atomic && result.Created:
+			b.rollback(ctx, result, ref, auths)
+			return err
+		case b.opts.Atomic, i == 0, errors.Is(err, release.ErrTagConflict):
+			// an existing tag is never deleted
+			return err
+		default:
+			// the tag of the primary remote stays, the failure is part of the result
+			b.log.Error("could not push tag to a secondary remote", "tag", result.Tag, "remote", remote, "error", err)
+		}
+	}
+	return nil
+}
+
+// rollback deletes the tag from the remotes it was pushed to and from the
+// repository. Failures are logged, as the push error is reported.
+func (b *Bumper) rollback(ctx context.Context, result *release.Result, ref *plumbing.Reference, auths []transport.AuthMethod) {
+	for i := range result.Remotes {
+		remoteResult := &result.Remotes[i]
+		if !remoteResult.Pushed {
+			continue
+		}
+		err := release.DeleteRemoteTag(ctx, b.repo, remoteResult.Remote, ref, auths[i], b.opts.Progress)
+		if err != nil {
+			b.log.Error("could not roll back the tag", "tag", result.Tag, "remote", remoteResult.Remote, "error", err)
+			continue
+		}
+		remoteResult.Pushed = false
+		remoteResult.RolledBack = true
+		b.log.Info("rolled back the tag", "tag", result.Tag, "remote", remoteResult.Remote)
+	}
+	result.Pushed = result.Remotes[0].Pushed
+	b.deleteTag(result)
+}
+
+// deleteTag deletes the created tag of the result from the repository.
+func (b *Bumper) deleteTag(result *release.Result) {
+	if err := b.repo.DeleteTag(result.Tag); err != nil {
+		b.log.Error("could not delete the tag", "tag", result.Tag, "error", err)
+		return
+	}
+	result.Created = false
+}
+
+// checkRemoteTag returns release.ErrTagConflict if the remote has the tag for
+// another commit, and release.ErrTagExists if it has the tag for the target.
+func (b *Bumper) checkRemoteTag(ctx context.Context, remote, name string, target plumbing.Hash, authMethod transport.AuthMethod) error {
+	_, commit, err := release.RemoteTag(ctx, b.repo, remote, name, authMethod)
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil
+	case err != nil:
+		// the push reports why the remote cannot be reached
+		b.log.Debug("could not read the tags of the remote", "remote", remote, "error", err)
+		return nil
+	case commit != target:
+		return fmt.Errorf("%w: %q points to %s on %q", release.ErrTagConflict, name, commit, remote)
+	}
+	return fmt.Errorf("%w: %q on %q", release.ErrTagExists, name, remote)
+}
+
+// verifyRemoteTag checks that the tag of the remote is the pushed tag, as
+// another job may have pushed the tag concurrently. If it is not, the push
+// error is replaced by release.ErrTagConflict.
+func (b *Bumper) verifyRemoteTag(ctx context.Context, remote string, ref *plumbing.Reference, authMethod transport.AuthMethod, pushErr error) error {
+	if pushErr != nil && !errors.Is(pushErr, release.ErrPushRejected) {
+		return pushErr
+	}
+	remoteRef, commit, err := release.RemoteTag(ctx, b.repo, remote, ref.Name().Short(), authMethod)
+	switch {
+	case pushErr != nil && err != nil:
+		return pushErr
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return &release.PushError{Remote: remote, Tag: ref.Name().Short(), Err: fmt.Errorf("the tag is missing after the push")}
+	case err != nil:
+		return err
+	case remoteRef.Hash() != ref.Hash():
+		return fmt.Errorf("%w: %q points to %s on %q", release.ErrTagConflict, ref.Name().Short(), commit, remote)
+	}
+	return nil
+}
+
+// refreshTags fetches the tags of the primary remote after another job
+// pushed a tag. A local tag of the same name is replaced by the remote one.
+func (b *Bumper) refreshTags(ctx context.Context) error {
+	remote := b.remotes()[0]
+	authMethod, err := b.auth(ctx, remote)
+	if err != nil {
+		return err
+	}
+	return release.FetchTags(ctx, b.repo, remote, false, authMethod, b.opts.Progress)
+}
+
+// remotes returns the remotes of the options, or origin.
+func (b *Bumper) remotes() []string {
+	if len(b.opts.Remotes) == 0 {
+		return []string{git.DefaultRemoteName}
+	}
+	return b.opts.Remotes
+}
+
+// auth returns the authentication of the push to the remote name or URL. Auth takes
+// precedence over AuthProvider, which resolves it by the URL of the remote.
+func (b *Bumper) auth(ctx context.Context, remoteName string) (transport.AuthMethod, error) {
+	remote, err := release.Remote(b.repo, remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read remote %q: %w", remoteName, err)
+	}
+	return b.authURL(ctx, remote.Config().URLs[0])
+}
+
+// auths returns the authentication of each remote.
+func (b *Bumper) auths(ctx context.Context, remotes []string) ([]transport.AuthMethod, error) {
+	auths := make([]transport.AuthMethod, len(remotes))
+	for i, remote := range remotes {
+		authMethod, err := b.auth(ctx, remote)
+		if err != nil {
+			return nil, err
+		}
+		auths[i] = authMethod
+	}
+	return auths, nil
+}
+
+// authURL returns the authentication for the URL. Auth takes precedence over
+// AuthProvider.
+func (b *Bumper) authURL(ctx context.Context, url string) (transport.AuthMethod, error) {
+	if b.opts.Auth != nil || b.opts.AuthProvider == nil {
+		return b.opts.Auth, nil
+	}
+	return auth.Resolve(ctx, b.opts.AuthProvider, url)
+}
+
+// createTagRef creates the tag in the repository for the target commit. A
+// signed tag is verified and removed again if its signature is invalid, so
+// it is never pushed.
+func (b *Bumper) createTagRef(name string, target plumbing.Hash, message string) (*plumbing.Reference, error) {
+	if b.opts.Signer == nil {
+		var options *git.CreateTagOptions
+		if !b.opts.Lightweight {
+			options = &git.CreateTagOptions{
+				Message: message,
+				Tagger:  b.opts.Tagger,
+			}
+		}
+		return release.CreateTag(b.repo, name, target, options)
+	}
+
+	ref, err := sign.CreateTag(b.repo, name, target, b.opts.Tagger, message, b.opts.Signer)
+	if err != nil {
+		return nil, err
+	}
+	if err := sign.VerifyTag(b.repo, ref, b.opts.Signer); err != nil {
+		if err := b.repo.DeleteTag(name); err != nil {
+			b.log.Warn("could not delete the tag with the invalid signature", "tag", name, "error", err)
+		}
+		return nil, err
+	}
+	b.log.Debug("verified the tag signature", "tag", name, "format", b.opts.Signer.Format())
+	return ref, nil
+}
+
+// writeChangelog renders the changelog section of the commits between the
+// previous version and the target commit, and prepends it to the changelog
+// file in the worktree. In explain mode, the file is not written.
+func (b *Bumper) writeChangelog(result *release.Result, previous *semver.Version, target plumbing.Hash) error {
+	commits, err := b.commitsSince(previous, target)
+	if err != nil {
+		return err
+	}
+	r := &changelog.Release{
+		Version:     result.Version,
+		Tag:         result.Tag,
+		PreviousTag: b.component.TagName(previous.Original()),
+		Date:        time.Now(),
+	}
+	r.Group(commits)
+	section, err := changelog.Render(b.opts.ChangelogTemplate, r)
+	if err != nil {
+		return err
+	}
+	result.Changelog = section
+
+	if b.opts.Explain {
+		b.trace.Addf("dry run: the changelog %q is not written", b.opts.Changelog)
+		return nil
+	}
+	wt, err := b.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("could not write changelog %q: %w", b.opts.Changelog, err)
+	}
+	existing, err := util.ReadFile(wt.Filesystem, b.opts.Changelog)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := util.WriteFile(wt.Filesystem, b.opts.Changelog, changelog.Prepend(existing, section), 0o644); err != nil {
+		return err
+	}
+	b.log.Info("wrote changelog", "path", b.opts.Changelog, "commits", len(commits))
+	return nil
+}
+
+// explain returns the steps of the decision trace. If Explain is not set,
+// nil is returned.
+func (b *Bumper) explain() []string {
+	if b.trace == nil {
+		return nil
+	}
+	return b.trace.Steps
+}
+
+// goModuleDirectory returns the directory of the Go module of the options,
+// the first path of the component or the repository root.
+func (b *Bumper) goModuleDirectory() string {
+	switch {
+	case b.opts.GoModuleDir != "":
+		return b.opts.GoModuleDir
+	case len(b.component.Paths) > 0:
+		return b.component.Paths[0]
+	}
+	return "."
+}
+
+// checkGoModule returns an error if the module path in the go.mod of the
+// target commit does not match the major version. If target is the zero hash,
+// the target of the options or HEAD is used.
+func (b *Bumper) checkGoModule(version *semver.Version, target plumbing.Hash) error {
+	if target.IsZero() {
+		head, err := b.head()
+		if err != nil {
+			return err
+		}
+		target = head
+	}
+	c, err := b.repo.CommitObject(target)
+	if err != nil {
+		return err
+	}
+	modulePath, err := gomod.Read(c, b.goModuleDirectory())
+	if err != nil {
+		return err
+	}
+	return gomod.Check(modulePath, version.Major())
+}
+
+// buildMetadata returns the build metadata of the options for the target
+// commit. If target is the zero hash, the target of the options or HEAD is
+// used.
+func (b *Bumper) buildMetadata(target plumbing.Hash) (string, error) {
+	if b.opts.MetadataFormat == "" {
+		return "", nil
+	}
+	if target.IsZero() {
+		head, err := b.head()
+		if err != nil {
+			return "", err
+		}
+		target = head
+	}
+	return release.BuildMetadata(b.opts.MetadataFormat, target.String(), b.opts.BuildNumber)
+}
+
+// commitsSince returns the commits of the component between the given tag and
+// the head commit. Commits are only filtered by the paths of the component if
+// it has any. If head is the zero hash, the target of the options or HEAD
+// is used. If the tag does not exist in the repository, all commits are
+// returned.
+func (b *Bumper) commitsSince(tag *semver.Version, head plumbing.Hash) ([]*object.Commit, error) {
+	if head.IsZero() {
+		target, err := b.head()
+		if err != nil {
+			return nil, err
+		}
+		head = target
+	}
+	base, err := release.ResolveTag(b.repo, b.component.TagName(tag.Original()))
+	if err != nil && !errors.Is(err, git.ErrTagNotFound) {
+		return nil, err
+	}
+	commits, err := commit.Log(b.repo, base, head)
+	if err != nil {
+		return nil, err
+	}
+	// without paths, every commit belongs to the component, even one without changes
+	if len(b.component.Paths) == 0 {
+		return commits, nil
+	}
+	return commit.Filter(commits, b.component.Contains)
+}
+
+// versionLine returns the version line of the branch of the options or of
+// the current branch. If the branch is not a maintenance branch, nil is returned.
+func (b *Bumper) versionLine() (*release.VersionLine, error) {
+	if len(b.opts.Config.Maintenance) == 0 {
+		return nil, nil
+	}
+	name, err := b.branchName()
+	if err != nil {
+		return nil, err
+	}
+	return branch.IdentifyBranchLine(b.opts.Config, name)
+}
+
+// resolveTarget resolves the commit of the target of the options. If the
+// target is a local or remote-tracking branch, it is the branch of the
+// branch rules.
+func (b *Bumper) resolveTarget() error {
+	if b.opts.Target == "" {
+		return nil
+	}
+	hash, err := b.repo.ResolveRevision(plumbing.Revision(b.opts.Target))
+	if err != nil {
+		return fmt.Errorf("%w: could not resolve the target %q: %w", ErrInvalidOptions, b.opts.Target, err)
+	}
+	b.target = *hash
+	b.trace.Addf("using the target %q (%s) instead of HEAD", b.opts.Target, b.target)
+
+	if _, err := b.repo.Reference(plumbing.NewBranchReferenceName(b.opts.Target), false); err == nil {
+		b.targetBranch = b.opts.Target
+	} else if _, err := b.repo.Reference(plumbing.ReferenceName("refs/remotes/"+b.opts.Target), false); err == nil {
+		// origin/main is the branch main of the remote origin
+		_, b.targetBranch, _ = strings.Cut(b.opts.Target, "/")
+	}
+	if b.targetBranch != "" {
+		b.trace.Addf("the target is the branch %q", b.targetBranch)
+	}
+	return nil
+}
+
+// head returns the target commit of the options, or HEAD.
+func (b *Bumper) head() (plumbing.Hash, error) {
+	if !b.target.IsZero() {
+		return b.target, nil
+	}
+	ref, err := b.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return ref.Hash(), nil
+}
+
+// branchName returns the branch of the branch rules: BranchName, the branch
+// of the target, or the current branch.
+func (b *Bumper) branchName() (string, error) {
+	switch {
+	case b.opts.BranchName != "":
+		return b.opts.BranchName, nil
+	case b.targetBranch != "":
+		return b.targetBranch, nil
+	}
+	return branch.Current(b.repo)
+}
@@ -0,0 +1,1162 @@
+package bump
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	gconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/leonsteinhaeuser/git-tag-bump/auth"
+	"github.com/leonsteinhaeuser/git-tag-bump/branch"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+	"github.com/leonsteinhaeuser/git-tag-bump/sign"
+	"golang.org/x/crypto/ssh"
+)
+
+var testSignature = &object.Signature{
+	Name:  "test",
+	Email: "test@example.com",
+	When:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// testRepo creates an in-memory repository with the given number of commits
+// and lightweight tags for the given commit indexes.
+func testRepo(t *testing.T, commits int, tags map[string]int) (*git.Repository, []plumbing.Hash) {
+	t.Helper()
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := []plumbing.Hash{}
+	for i := 0; i < commits; i++ {
+		hash, err := wt.Commit("commit", &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            testSignature,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	for name, i := range tags {
+		if _, err := repo.CreateTag(name, hashes[i], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo, hashes
+}
+
+// testSigner returns an SSH signer with a new ed25519 key.
+func testSigner(t *testing.T) sign.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := sign.NewSSH(pem.EncodeToMemory(block), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestNew(t *testing.T) {
+	repo, _ := testRepo(t, 1, nil)
+	tests := []struct {
+		name    string
+		opts    func(o *Options)
+		wantErr error
+	}{
+		{
+			name: "defaults",
+			opts: func(o *Options) {},
+		},
+		{
+			name: "annotated tag without tagger",
+			opts: func(o *Options) {
+				o.Create = true
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "signed lightweight tag",
+			opts: func(o *Options) {
+				o.Create = true
+				o.Lightweight = true
+				o.Signer = testSigner(t)
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "unknown remote",
+			opts: func(o *Options) {
+				o.Create = true
+				o.Lightweight = true
+				o.Remotes = []string{"mirror"}
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "invalid branch rule",
+			opts: func(o *Options) {
+				o.Config = &branch.Config{Major: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "(["}}}}
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "unknown bump type",
+			opts: func(o *Options) {
+				o.BumpType = "majr"
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "unknown shallow mode",
+			opts: func(o *Options) {
+				o.Shallow = "unshallow"
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "changelog of a remote repository",
+			opts: func(o *Options) {
+				o.Repository = nil
+				o.RepoURL = "https://example.com/project.git"
+				o.Changelog = "CHANGELOG.md"
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "unknown component",
+			opts: func(o *Options) {
+				o.Component = "api"
+			},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "unknown go module check",
+			opts: func(o *Options) {
+				o.GoModule = "fail"
+			},
+			wantErr: ErrInvalidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Repository = repo
+			tt.opts(&opts)
+			_, err := New(opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBumper_Run(t *testing.T) {
+	repo, hashes := testRepo(t, 3, map[string]int{"v1.0.0": 0, "v1.1.0-rc.1": 1})
+	cfg := &branch.Config{
+		Major: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "^feat!/"}}},
+		Minor: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "^feat/"}}},
+		Patch: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "^fix/"}}},
+	}
+	tests := []struct {
+		name    string
+		opts    func(o *Options)
+		want    release.Result
+		wantErr error
+	}{
+		{
+			name: "patch",
+			opts: func(o *Options) {},
+			want: release.Result{
+				PreviousTag: "v1.0.0",
+				Tag:         "v1.0.1",
+				Version:     "1.0.1",
+				Major:       1,
+				Patch:       1,
+				BumpType:    release.SemVerBumpTypePatch,
+				Reason:      "set by the options",
+				Commit:      hashes[2].String(),
+			},
+		},
+		{
+			name: "branch rule",
+			opts: func(o *Options) {
+				o.Config = cfg
+				o.BranchName = "feat/login"
+				o.VPrefix = false
+			},
+			want: release.Result{
+				PreviousTag: "v1.0.0",
+				Tag:         "1.1.0",
+				Version:     "1.1.0",
+				Major:       1,
+				Minor:       1,
+				BumpType:    release.SemVerBumpTypeMinor,
+				Reason:      `the branch "feat/login" matches the rule "^feat/"`,
+				Commit:      hashes[2].String(),
+			},
+		},
+		{
+			name: "no matching rule",
+			opts: func(o *Options) {
+				o.Config = cfg
+				o.BranchName = "docs/readme"
+			},
+			wantErr: branch.ErrNoMatchingRule,
+		},
+		{
+			name: "branch without rules",
+			opts: func(o *Options) {
+				o.BranchName = "docs/typo"
+			},
+			wantErr: branch.ErrNoMatchingRule,
+		},
+		{
+			name: "pre-release",
+			opts: func(o *Options) {
+				o.PreRelease = true
+				o.BumpType = release.SemVerBumpTypeNone
+			},
+			want: release.Result{
+				PreviousTag: "v1.1.0-rc.1",
+				Tag:         "v1.1.0-rc.2",
+				Version:     "1.1.0-rc.2",
+				Major:       1,
+				Minor:       1,
+				PreRelease:  "rc.2",
+				BumpType:    release.SemVerBumpTypeNone,
+				Reason:      "set by the options",
+				Commit:      hashes[2].String(),
+			},
+		},
+		{
+			name: "no release needed",
+			opts: func(o *Options) {
+				o.BumpType = release.SemVerBumpTypeNone
+			},
+			want: release.Result{
+				PreviousTag: "v1.0.0",
+				Tag:         "v1.0.0",
+				Version:     "1.0.0",
+				Major:       1,
+				BumpType:    release.SemVerBumpTypeNone,
+				Reason:      `bump type none keeps the version "v1.0.0"`,
+				Commit:      hashes[0].String(),
+			},
+			wantErr: release.ErrNoReleaseNeeded,
+		},
+		{
+			name: "promote",
+			opts: func(o *Options) {
+				o.Promote = true
+				o.PromoteCommit = true
+			},
+			want: release.Result{
+				PreviousTag: "v1.1.0-rc.1",
+				Tag:         "v1.1.0",
+				Version:     "1.1.0",
+				Major:       1,
+				Minor:       1,
+				BumpType:    release.SemVerBumpTypeNone,
+				Reason:      `promotion of the pre-release "v1.1.0-rc.1"`,
+				Commit:      hashes[1].String(),
+			},
+		},
+		{
+			name: "invalid base tag",
+			opts: func(o *Options) {
+				o.BaseTag = "latest"
+			},
+			wantErr: ErrInvalidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Repository = repo
+			tt.opts(&opts)
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bumper.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got == nil {
+				if tt.want.Tag != "" {
+					t.Errorf("Bumper.Run() = nil, want %+v", tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Bumper.Run() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_Create(t *testing.T) {
+	dir := t.TempDir()
+	remote, err := git.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, hashes := testRepo(t, 2, map[string]int{"v1.0.0": 0})
+	if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.Repository = repo
+	opts.Create = true
+	opts.Tagger = testSignature
+	opts.TagMessageTemplate = "Release {{ .Tag }} ({{ .BumpType }} since {{ .PreviousTag }})"
+	b, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Created || !got.Pushed {
+		t.Errorf("Bumper.Run() created = %v, pushed = %v, want both", got.Created, got.Pushed)
+	}
+	hash, err := release.ResolveTag(remote, "v1.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != hashes[1] {
+		t.Errorf("remote tag points to %v, want %v", hash, hashes[1])
+	}
+	ref, err := remote.Tag("v1.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := remote.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Release v1.0.1 (patch since v1.0.0)\n"; tag.Message != want {
+		t.Errorf("tag message = %q, want %q", tag.Message, want)
+	}
+}
+
+func TestBumper_Run_Remotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing map[int]bool
+		atomic  bool
+		// want are the remote results, wantTags the remotes with the tag
+		want        []release.RemoteResult
+		wantTags    []bool
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			name:        "all remotes",
+			want:        []release.RemoteResult{{Pushed: true}, {Pushed: true}},
+			wantTags:    []bool{true, true},
+			wantCreated: true,
+		},
+		{
+			name:        "secondary remote fails",
+			missing:     map[int]bool{1: true},
+			want:        []release.RemoteResult{{Pushed: true}, {Error: "set"}},
+			wantTags:    []bool{true, false},
+			wantCreated: true,
+		},
+		{
+			name:     "secondary remote fails in atomic mode",
+			missing:  map[int]bool{1: true},
+			atomic:   true,
+			want:     []release.RemoteResult{{RolledBack: true}, {Error: "set"}},
+			wantTags: []bool{false, false},
+			wantErr:  release.ErrPushRejected,
+		},
+		{
+			name:        "primary remote fails",
+			missing:     map[int]bool{0: true},
+			want:        []release.RemoteResult{{Error: "set"}},
+			wantTags:    []bool{false, false},
+			wantCreated: true,
+			wantErr:     release.ErrPushRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := testRepo(t, 2, map[string]int{"v1.0.0": 0})
+			dirs := []string{}
+			remotes := []*git.Repository{}
+			for i := 0; i < 2; i++ {
+				dir := t.TempDir()
+				remote, err := git.PlainInit(dir, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.missing[i] {
+					dir += "/missing.git"
+				}
+				dirs = append(dirs, dir)
+				remotes = append(remotes, remote)
+			}
+			// the primary remote is configured, the secondary passed by its path
+			if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: "primary", URLs: []string{dirs[0]}}); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := DefaultOptions()
+			opts.Repository = repo
+			opts.Create = true
+			opts.Lightweight = true
+			opts.Remotes = []string{"primary", dirs[1]}
+			opts.Atomic = tt.atomic
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bumper.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got.Remotes) != len(tt.want) {
+				t.Fatalf("Bumper.Run() remotes = %+v, want %+v", got.Remotes, tt.want)
+			}
+			for i, want := range tt.want {
+				remoteResult := got.Remotes[i]
+				if remoteResult.Remote != opts.Remotes[i] || remoteResult.Pushed != want.Pushed || remoteResult.RolledBack != want.RolledBack || (remoteResult.Error != "") != (want.Error != "") {
+					t.Errorf("Bumper.Run() remote %d = %+v, want %+v", i, remoteResult, want)
+				}
+			}
+			if got.Pushed != tt.want[0].Pushed || got.Created != tt.wantCreated {
+				t.Errorf("Bumper.Run() pushed = %v, created = %v, want %v, %v", got.Pushed, got.Created, tt.want[0].Pushed, tt.wantCreated)
+			}
+			for i, remote := range remotes {
+				_, err := remote.Tag("v1.0.1")
+				if hasTag := err == nil; hasTag != tt.wantTags[i] {
+					t.Errorf("remote %d has tag = %v, want %v", i, hasTag, tt.wantTags[i])
+				}
+			}
+			if _, err := repo.Tag("v1.0.1"); (err == nil) != tt.wantCreated {
+				t.Errorf("local tag error = %v, want created %v", err, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_FetchTags(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	upstream, _ := testRepo(t, 3, map[string]int{"v1.0.0": 0, "v1.1.0": 1})
+	if _, err := upstream.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+	err := upstream.Push(&git.PushOptions{RefSpecs: []gconfig.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    func(o *Options)
+		want    string
+		wantErr error
+	}{
+		{
+			// the tags are missing, so the version starts over
+			name: "without fetch",
+			opts: func(o *Options) {},
+			want: "v0.0.1",
+		},
+		{
+			name: "shallow clone",
+			opts: func(o *Options) {
+				o.FetchTags = true
+			},
+			wantErr: release.ErrShallow,
+		},
+		{
+			name: "deepen shallow clone",
+			opts: func(o *Options) {
+				o.FetchTags = true
+				o.Shallow = ShallowDeepen
+			},
+			want: "v1.1.1",
+		},
+		{
+			// a dry run does not fetch the tags
+			name: "explain",
+			opts: func(o *Options) {
+				o.FetchTags = true
+				o.Explain = true
+			},
+			want: "v0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a CI checkout of depth one without tags
+			repo, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{URL: dir, Depth: 1, Tags: git.NoTags})
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := DefaultOptions()
+			opts.Repository = repo
+			tt.opts(&opts)
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bumper.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Tag != tt.want {
+				t.Errorf("Bumper.Run() tag = %v, want %v", got.Tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_RepoURL(t *testing.T) {
+	dir := t.TempDir()
+	remote, err := git.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream, hashes := testRepo(t, 1, nil)
+	if _, err := upstream.CreateTag("v1.1.0", hashes[0], &git.CreateTagOptions{Tagger: testSignature, Message: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := upstream.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := util.WriteFile(wt.Filesystem, "file.txt", []byte("login"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	head, err := wt.Commit("feat: add login", &git.CommitOptions{Author: testSignature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := upstream.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+	err = upstream.Push(&git.PushOptions{RefSpecs: []gconfig.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts func(o *Options)
+		want string
+	}{
+		{
+			name: "references only",
+			opts: func(o *Options) {},
+			want: "v1.1.1",
+		},
+		{
+			name: "commit analysis",
+			opts: func(o *Options) {
+				o.ConventionalCommits = true
+			},
+			want: "v1.2.0",
+		},
+		{
+			name: "create",
+			opts: func(o *Options) {
+				o.Create = true
+				o.Lightweight = true
+				o.BumpType = release.SemVerBumpTypeMajor
+			},
+			want: "v2.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.RepoPath = t.TempDir()
+			opts.RepoURL = "file://" + dir
+			tt.opts(&opts)
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Bumper.Run() error = %v", err)
+			}
+			if got.Tag != tt.want || got.Commit != head.String() {
+				t.Errorf("Bumper.Run() tag = %v, commit = %v, want %v, %v", got.Tag, got.Commit, tt.want, head)
+			}
+			if !opts.Create {
+				return
+			}
+			if hash, err := release.ResolveTag(remote, tt.want); err != nil || hash != head {
+				t.Errorf("remote tag %q = %v, %v, want %v", tt.want, hash, err, head)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_AuthMissing(t *testing.T) {
+	repo, _ := testRepo(t, 2, map[string]int{"v1.0.0": 0})
+	if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{"https://git.example.com/project.git"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.Repository = repo
+	opts.Create = true
+	opts.Lightweight = true
+	// the netrc file does not exist, so no provider has credentials
+	opts.AuthProvider = auth.Providers{&auth.Netrc{Path: t.TempDir() + "/.netrc"}}
+	b, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Run(context.Background()); !errors.Is(err, release.ErrAuthMissing) {
+		t.Errorf("Bumper.Run() error = %v, wantErr %v", err, release.ErrAuthMissing)
+	}
+	if _, err := repo.Tag("v1.0.1"); !errors.Is(err, git.ErrTagNotFound) {
+		t.Errorf("tag v1.0.1 error = %v, want it not to be created", err)
+	}
+}
+
+func TestBumper_Run_Conflict(t *testing.T) {
+	tests := []struct {
+		name string
+		// remoteTag is the commit index of the v1.0.1 tag another job pushed
+		remoteTag int
+		retries   int
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "tag pushed for another commit",
+			remoteTag: 1,
+			retries:   3,
+			want:      "v1.0.2",
+		},
+		{
+			name:      "no retries left",
+			remoteTag: 1,
+			wantErr:   release.ErrTagConflict,
+		},
+		{
+			name:      "tag pushed for the target",
+			remoteTag: 2,
+			retries:   3,
+			wantErr:   release.ErrTagExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, hashes := testRepo(t, 3, map[string]int{"v1.0.0": 0})
+			dir := t.TempDir()
+			remote, err := git.PlainInit(dir, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+				t.Fatal(err)
+			}
+			// another job pushed v1.0.1 after the tags were read
+			if _, err := repo.CreateTag("v1.0.1", hashes[tt.remoteTag], nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Push(&git.PushOptions{RefSpecs: []gconfig.RefSpec{"refs/tags/v1.0.1:refs/tags/v1.0.1"}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.DeleteTag("v1.0.1"); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := DefaultOptions()
+			opts.Repository = repo
+			opts.Create = true
+			opts.Lightweight = true
+			opts.Retries = tt.retries
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bumper.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, err := repo.Tag("v1.0.1"); !errors.Is(err, git.ErrTagNotFound) {
+					t.Errorf("tag v1.0.1 error = %v, want it not to be created", err)
+				}
+				return
+			}
+			if got.Tag != tt.want || !got.Pushed {
+				t.Errorf("Bumper.Run() = %v, pushed %v, want %v", got.Tag, got.Pushed, tt.want)
+			}
+			ref, err := remote.Tag(tt.want)
+			if err != nil || ref.Hash() != hashes[2] {
+				t.Errorf("remote tag %v = %v, %v, want %v", tt.want, ref, err, hashes[2])
+			}
+		})
+	}
+}
+
+func TestBumper_Run_Existing(t *testing.T) {
+	tests := []struct {
+		name         string
+		tags         map[string]int
+		forceTag     bool
+		preRelease   bool
+		pushExisting bool
+		want         string
+		wantExisting bool
+		// wantRemote is true if the tag is pushed to the remote
+		wantRemote bool
+	}{
+		{
+			name:         "existing tag",
+			tags:         map[string]int{"v1.0.0": 0, "v1.0.1": 1},
+			want:         "v1.0.1",
+			wantExisting: true,
+		},
+		{
+			name:         "existing tag pushed again",
+			tags:         map[string]int{"v1.0.0": 0, "v1.0.1": 1},
+			pushExisting: true,
+			want:         "v1.0.1",
+			wantExisting: true,
+			wantRemote:   true,
+		},
+		{
+			name:       "forced new tag",
+			tags:       map[string]int{"v1.0.0": 0, "v1.0.1": 1},
+			forceTag:   true,
+			want:       "v1.0.2",
+			wantRemote: true,
+		},
+		{
+			name:       "pre-release of the commit for a release",
+			tags:       map[string]int{"v1.0.0": 0, "v1.0.1-rc.1": 1},
+			want:       "v1.0.1",
+			wantRemote: true,
+		},
+		{
+			name:         "pre-release of the commit for a pre-release",
+			tags:         map[string]int{"v1.0.0": 0, "v1.0.1-rc.1": 1},
+			preRelease:   true,
+			want:         "v1.0.1-rc.1",
+			wantExisting: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, hashes := testRepo(t, 2, tt.tags)
+			dir := t.TempDir()
+			remote, err := git.PlainInit(dir, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := DefaultOptions()
+			opts.Repository = repo
+			opts.Create = true
+			opts.Lightweight = true
+			opts.ForceTag = tt.forceTag
+			opts.PreRelease = tt.preRelease
+			opts.PushExisting = tt.pushExisting
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Bumper.Run() error = %v", err)
+			}
+			if got.Tag != tt.want || got.Existing != tt.wantExisting || got.Created == tt.wantExisting || got.Pushed != tt.wantRemote {
+				t.Errorf("Bumper.Run() = %v, existing %v, created %v, pushed %v, want %v, %v, %v, %v",
+					got.Tag, got.Existing, got.Created, got.Pushed, tt.want, tt.wantExisting, !tt.wantExisting, tt.wantRemote)
+			}
+			if got.Commit != hashes[1].String() {
+				t.Errorf("Bumper.Run() commit = %v, want %v", got.Commit, hashes[1])
+			}
+			if _, err := remote.Tag(tt.want); (err == nil) != tt.wantRemote {
+				t.Errorf("remote tag %v error = %v, want pushed %v", tt.want, err, tt.wantRemote)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_Target(t *testing.T) {
+	cfg := &branch.Config{
+		Major: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "^feat!/"}}},
+		Minor: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "^feat/"}}},
+		Patch: branch.Identifier{Branch: branch.BranchIdentifier{Name: branch.RegExIdentifier{RegEx: "^fix/"}}},
+	}
+	tests := []struct {
+		name       string
+		target     string
+		autoBump   bool
+		want       string
+		wantReason string
+		wantErr    error
+	}{
+		{
+			name:       "short commit hash",
+			target:     "short",
+			want:       "v1.0.1",
+			wantReason: "set by the options",
+		},
+		{
+			name:       "annotated tag",
+			target:     "candidate",
+			want:       "v1.0.1",
+			wantReason: "set by the options",
+		},
+		{
+			name:       "branch",
+			target:     "feat/login",
+			autoBump:   true,
+			want:       "v1.1.0",
+			wantReason: `the branch "feat/login" of the target matches the rule "^feat/"`,
+		},
+		{
+			name:       "remote-tracking branch",
+			target:     "origin/fix/typo",
+			autoBump:   true,
+			want:       "v1.0.1",
+			wantReason: `the branch "fix/typo" of the target matches the rule "^fix/"`,
+		},
+		{
+			name:    "unknown revision",
+			target:  "missing",
+			wantErr: ErrInvalidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// HEAD is the third commit, the target the second one
+			repo, hashes := testRepo(t, 3, map[string]int{"v1.0.0": 0})
+			refs := []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("feat/login"), hashes[1]),
+				plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "fix/typo"), hashes[1]),
+			}
+			for _, ref := range refs {
+				if err := repo.Storer.SetReference(ref); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := repo.CreateTag("candidate", hashes[1], &git.CreateTagOptions{Tagger: testSignature, Message: "candidate"}); err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			remote, err := git.PlainInit(dir, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := DefaultOptions()
+			opts.Repository = repo
+			opts.Config = cfg
+			opts.Create = true
+			opts.Lightweight = true
+			opts.Target = tt.target
+			if tt.target == "short" {
+				opts.Target = hashes[1].String()[:7]
+			}
+			opts.AutoBump = tt.autoBump
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bumper.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Tag != tt.want || got.Reason != tt.wantReason || got.Commit != hashes[1].String() {
+				t.Errorf("Bumper.Run() = %v, %q, %v, want %v, %q, %v", got.Tag, got.Reason, got.Commit, tt.want, tt.wantReason, hashes[1])
+			}
+			hash, err := release.ResolveTag(remote, tt.want)
+			if err != nil || hash != hashes[1] {
+				t.Errorf("remote tag %v = %v, %v, want %v", tt.want, hash, err, hashes[1])
+			}
+		})
+	}
+}
+
+func TestBumper_Run_Sign(t *testing.T) {
+	dir := t.TempDir()
+	remote, err := git.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, _ := testRepo(t, 2, map[string]int{"v1.0.0": 0})
+	if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := testSigner(t)
+	opts := DefaultOptions()
+	opts.Repository = repo
+	opts.Create = true
+	opts.Tagger = testSignature
+	opts.Signer = signer
+	b, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Created || !got.Pushed {
+		t.Errorf("Bumper.Run() created = %v, pushed = %v, want both", got.Created, got.Pushed)
+	}
+	ref, err := remote.Tag("v1.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sign.VerifyTag(remote, ref, signer); err != nil {
+		t.Errorf("remote tag signature: %v", err)
+	}
+}
+
+func TestBumper_Run_Component(t *testing.T) {
+	cfg := &branch.Config{
+		Components: []release.Component{{Name: "api", TagPrefix: "api/", Paths: []string{"api"}}},
+	}
+	tests := []struct {
+		name string
+		// files are the changed files of the commits after the first one
+		files   []string
+		tagged  bool
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "changes since the tag",
+			files:  []string{"api/main.go", "web/index.html"},
+			tagged: true,
+			want:   "api/v1.0.1",
+		},
+		{
+			name:    "no changes since the tag",
+			files:   []string{"web/index.html"},
+			tagged:  true,
+			want:    "api/v1.0.0",
+			wantErr: release.ErrNoReleaseNeeded,
+		},
+		{
+			name:    "no changes without tag",
+			files:   []string{"web/index.html"},
+			wantErr: release.ErrNoTags,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := map[string]int{}
+			if tt.tagged {
+				tags["api/v1.0.0"] = 0
+			}
+			repo, _ := testRepo(t, 1, tags)
+			wt, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range tt.files {
+				if err := util.WriteFile(wt.Filesystem, file, []byte(file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := wt.Add(file); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := wt.Commit("change "+file, &git.CommitOptions{Author: testSignature}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			opts := DefaultOptions()
+			opts.Repository = repo
+			opts.Config = cfg
+			opts.Component = "api"
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bumper.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("Bumper.Run() = %v, want no result", got.Tag)
+				}
+				return
+			}
+			if got.Tag != tt.want {
+				t.Errorf("Bumper.Run() = %v, want %v", got.Tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_ConventionalCommits(t *testing.T) {
+	tests := []struct {
+		name   string
+		tagged bool
+		// empty commits do not change any file
+		empty    bool
+		messages []string
+		want     string
+	}{
+		{
+			name:     "feature since the tag",
+			tagged:   true,
+			messages: []string{"feat: add login", "fix: handle empty tags"},
+			want:     "v1.1.0",
+		},
+		{
+			name:     "empty breaking commit",
+			tagged:   true,
+			empty:    true,
+			messages: []string{"feat!: drop the old API"},
+			want:     "v2.0.0",
+		},
+		{
+			name:     "repository without tags",
+			messages: []string{"feat: add login"},
+			want:     "v0.1.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := map[string]int{}
+			if tt.tagged {
+				tags["v1.0.0"] = 0
+			}
+			repo, _ := testRepo(t, 1, tags)
+			wt, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, msg := range tt.messages {
+				if !tt.empty {
+					if err := util.WriteFile(wt.Filesystem, "file.txt", []byte(msg), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					if _, err := wt.Add("file.txt"); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if _, err := wt.Commit(msg, &git.CommitOptions{Author: testSignature, AllowEmptyCommits: tt.empty}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			opts := DefaultOptions()
+			opts.Repository = repo
+			opts.ConventionalCommits = true
+			b, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Bumper.Run() error = %v", err)
+			}
+			if got.Tag != tt.want {
+				t.Errorf("Bumper.Run() = %v, want %v", got.Tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumper_Run_Changelog(t *testing.T) {
+	repo, hashes := testRepo(t, 1, map[string]int{"v1.0.0": 0})
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"feat(api): add login", "fix: handle empty tags", "docs: update readme"} {
+		if err := util.WriteFile(wt.Filesystem, "file.txt", []byte(msg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add("file.txt"); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := wt.Commit(msg, &git.CommitOptions{Author: testSignature})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := util.WriteFile(wt.Filesystem, "CHANGELOG.md", []byte("# Changelog\n\n## [1.0.0] - 2024-01-01\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.Repository = repo
+	opts.ConventionalCommits = true
+	opts.Changelog = "CHANGELOG.md"
+	b, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Now().Format("2006-01-02")
+	wantSection := "## [1.1.0] - " + date + "\n\n### Features\n\n- **api:** add login (" + hashes[1].String()[:7] + ")\n\n### Fixes\n\n- handle empty tags (" + hashes[2].String()[:7] + ")\n"
+	if got.Changelog != wantSection {
+		t.Errorf("Bumper.Run() changelog = %q, want %q", got.Changelog, wantSection)
+	}
+	content, err := util.ReadFile(wt.Filesystem, "CHANGELOG.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Changelog\n\n" + wantSection + "\n## [1.0.0] - 2024-01-01\n"; string(content) != want {
+		t.Errorf("CHANGELOG.md = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,154 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/leonsteinhaeuser/git-tag-bump/commit"
+)
+
+var (
+	ErrTemplate = fmt.Errorf("changelog template is invalid")
+)
+
+// Header is written at the top of a new changelog file.
+const Header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+`
+
+// DefaultTemplate renders a release in the Keep a Changelog style. Every
+// group is only rendered if it has entries.
+const DefaultTemplate = `## [{{ .Version }}] - {{ .Date.Format "2006-01-02" }}
+{{ with .Breaking }}
+### Breaking Changes
+
+{{ range . }}{{ template "entry" . }}{{ end }}{{ end }}
+{{- with .Features }}
+### Features
+
+{{ range . }}{{ template "entry" . }}{{ end }}{{ end }}
+{{- with .Fixes }}
+### Fixes
+
+{{ range . }}{{ template "entry" . }}{{ end }}{{ end }}
+{{- define "entry" }}- {{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ .Description }} ({{ .ShortSHA }})
+{{ end }}`
+
+// Entry is a commit listed in the changelog.
+type Entry struct {
+	SHA         string
+	Scope       string
+	Description string
+}
+
+// ShortSHA returns the first seven characters of the commit hash.
+func (e Entry) ShortSHA() string {
+	return shortSHA(e.SHA)
+}
+
+// shortSHA returns the first seven characters of a commit hash.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// Release is the changelog section of a release. It is the data passed to
+// the template.
+type Release struct {
+	Version     string
+	Tag         string
+	PreviousTag string
+	Date        time.Time
+	Breaking    []Entry
+	Features    []Entry
+	Fixes       []Entry
+}
+
+// Group adds the commits to the release grouped by their Conventional Commit
+// type. Breaking changes are only listed as such, commits of other types and
+// commits that are not conventional commits are ignored.
+func (r *Release) Group(commits []*object.Commit) {
+	for _, c := range commits {
+		cc, err := commit.Parse(c.Message)
+		if err != nil {
+			continue
+		}
+		entry := Entry{SHA: c.Hash.String(), Scope: cc.Scope, Description: cc.Description}
+		switch {
+		case cc.Breaking:
+			// the footer describes the breaking change in more detail than the header
+			if note := breakingNote(cc); note != "" {
+				entry.Description = note
+			}
+			r.Breaking = append(r.Breaking, entry)
+		case cc.Type == "feat":
+			r.Features = append(r.Features, entry)
+		case cc.Type == "fix":
+			r.Fixes = append(r.Fixes, entry)
+		}
+	}
+}
+
+// breakingNote returns the value of the breaking change footer.
+func breakingNote(cc *commit.Conventional) string {
+	if note, ok := cc.Footers["BREAKING CHANGE"]; ok {
+		return note
+	}
+	return cc.Footers["BREAKING-CHANGE"]
+}
+
+// Render renders the release with the given text/template. An empty template
+// uses DefaultTemplate.
+func Render(tmpl string, r *Release) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	return render("changelog", tmpl, r)
+}
+
+// render executes the text/template with the given data. The output is
+// trimmed and ends with a newline.
+func render(name, tmpl string, data any) (string, error) {
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
+
+// Prepend adds the rendered section on top of the released versions of the
+// changelog, i.e. below the header and the Unreleased section. An empty
+// changelog starts with Header.
+func Prepend(changelog []byte, section string) []byte {
+	content := string(changelog)
+	if strings.TrimSpace(content) == "" {
+		return []byte(Header + "\n" + section)
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "## ") && !strings.HasPrefix(strings.ToLower(line), "## [unreleased]") {
+			return []byte(content[:offset] + section + "\n" + content[offset:])
+		}
+		offset += len(line)
+	}
+	// no released version yet, so the section is appended
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return []byte(content + "\n" + section)
+}
@@ -0,0 +1,190 @@
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var testDate = time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+// testCommits returns one commit per message with increasing hashes.
+func testCommits(messages ...string) []*object.Commit {
+	commits := []*object.Commit{}
+	for i, msg := range messages {
+		commits = append(commits, &object.Commit{
+			Hash:    plumbing.NewHash(fmt.Sprintf("%040d", i+1)),
+			Message: msg,
+		})
+	}
+	return commits
+}
+
+func TestEntry_ShortSHA(t *testing.T) {
+	tests := []struct {
+		name string
+		sha  string
+		want string
+	}{
+		{
+			name: "full hash",
+			sha:  "abc1234def5678abc1234def5678abc1234def56",
+			want: "abc1234",
+		},
+		{
+			name: "short hash",
+			sha:  "abc",
+			want: "abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Entry{SHA: tt.sha}).ShortSHA(); got != tt.want {
+				t.Errorf("Entry.ShortSHA() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelease_Group(t *testing.T) {
+	commits := testCommits(
+		"feat(api): add login",
+		"fix: handle empty tags",
+		"chore: update deps",
+		"refactor!: drop the v1 api",
+		"fix: remove flag\n\nBREAKING CHANGE: the --legacy flag was removed",
+		"update readme",
+	)
+	r := &Release{}
+	r.Group(commits)
+
+	want := &Release{
+		Breaking: []Entry{
+			{SHA: commits[3].Hash.String(), Description: "drop the v1 api"},
+			{SHA: commits[4].Hash.String(), Description: "the --legacy flag was removed"},
+		},
+		Features: []Entry{
+			{SHA: commits[0].Hash.String(), Scope: "api", Description: "add login"},
+		},
+		Fixes: []Entry{
+			{SHA: commits[1].Hash.String(), Description: "handle empty tags"},
+		},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("Release.Group() = %+v, want %+v", r, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := &Release{
+		Version: "1.2.0",
+		Tag:     "v1.2.0",
+		Date:    testDate,
+		Breaking: []Entry{
+			{SHA: "1111111111", Description: "drop the v1 api"},
+		},
+		Features: []Entry{
+			{SHA: "2222222222", Scope: "api", Description: "add login"},
+			{SHA: "3333333333", Description: "add logout"},
+		},
+	}
+	type args struct {
+		tmpl string
+		r    *Release
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default template",
+			args: args{r: r},
+			want: `## [1.2.0] - 2026-10-17
+
+### Breaking Changes
+
+- drop the v1 api (1111111)
+
+### Features
+
+- **api:** add login (2222222)
+- add logout (3333333)
+`,
+		},
+		{
+			name: "no changes",
+			args: args{r: &Release{Version: "1.2.1", Date: testDate}},
+			want: "## [1.2.1] - 2026-10-17\n",
+		},
+		{
+			name: "custom template",
+			args: args{tmpl: "# {{ .Tag }}\n{{ range .Features }}* {{ .Description }}\n{{ end }}", r: r},
+			want: "# v1.2.0\n* add login\n* add logout\n",
+		},
+		{
+			name:    "invalid template",
+			args:    args{tmpl: "{{ .Tag ", r: r},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			args:    args{tmpl: "{{ .Unknown }}", r: r},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.args.tmpl, tt.args.r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	section := "## [1.2.0] - 2026-10-17\n"
+	tests := []struct {
+		name      string
+		changelog string
+		want      string
+	}{
+		{
+			name:      "new changelog",
+			changelog: "",
+			want:      Header + "\n" + section,
+		},
+		{
+			name:      "released versions",
+			changelog: "# Changelog\n\n## [1.1.0] - 2026-01-01\n",
+			want:      "# Changelog\n\n## [1.2.0] - 2026-10-17\n\n## [1.1.0] - 2026-01-01\n",
+		},
+		{
+			name:      "unreleased section",
+			changelog: "# Changelog\n\n## [Unreleased]\n\n- wip\n\n## [1.1.0] - 2026-01-01\n",
+			want:      "# Changelog\n\n## [Unreleased]\n\n- wip\n\n## [1.2.0] - 2026-10-17\n\n## [1.1.0] - 2026-01-01\n",
+		},
+		{
+			name:      "no released versions",
+			changelog: "# Changelog",
+			want:      "# Changelog\n\n## [1.2.0] - 2026-10-17\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Prepend([]byte(tt.changelog), section)); got != tt.want {
+				t.Errorf("Prepend() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package changelog
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+// Message is the data passed to the template of an annotated tag message.
+type Message struct {
+	PreviousTag string
+	Tag         string
+	Version     string
+	BumpType    release.SemVerBumpType
+	Reason      string
+	// Commits are the commits since the previous tag, from the newest to the oldest.
+	Commits []Commit
+}
+
+// Commit is a commit listed in a tag message.
+type Commit struct {
+	SHA     string
+	Subject string
+	Message string
+}
+
+// ShortSHA returns the first seven characters of the commit hash.
+func (c Commit) ShortSHA() string {
+	return shortSHA(c.SHA)
+}
+
+// NewMessage returns the tag message data of the result and the commits
+// since the previous tag.
+func NewMessage(result *release.Result, commits []*object.Commit) *Message {
+	m := &Message{
+		PreviousTag: result.PreviousTag,
+		Tag:         result.Tag,
+		Version:     result.Version,
+		BumpType:    result.BumpType,
+		Reason:      result.Reason,
+		Commits:     []Commit{},
+	}
+	for _, c := range commits {
+		message := strings.TrimSpace(c.Message)
+		subject, _, _ := strings.Cut(message, "\n")
+		m.Commits = append(m.Commits, Commit{SHA: c.Hash.String(), Subject: subject, Message: message})
+	}
+	return m
+}
+
+// RenderMessage renders the tag message with the given text/template.
+func RenderMessage(tmpl string, m *Message) (string, error) {
+	return render("message", tmpl, m)
+}
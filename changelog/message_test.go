@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+func TestNewMessage(t *testing.T) {
+	commits := testCommits("feat: add login\n\nWith a body.\n", "fix: handle empty tags")
+	result := &release.Result{
+		PreviousTag: "v1.1.0",
+		Tag:         "v1.2.0",
+		Version:     "1.2.0",
+		BumpType:    release.SemVerBumpTypeMinor,
+		Reason:      "passed by --bump",
+	}
+	want := &Message{
+		PreviousTag: "v1.1.0",
+		Tag:         "v1.2.0",
+		Version:     "1.2.0",
+		BumpType:    release.SemVerBumpTypeMinor,
+		Reason:      "passed by --bump",
+		Commits: []Commit{
+			{SHA: commits[0].Hash.String(), Subject: "feat: add login", Message: "feat: add login\n\nWith a body."},
+			{SHA: commits[1].Hash.String(), Subject: "fix: handle empty tags", Message: "fix: handle empty tags"},
+		},
+	}
+	if got := NewMessage(result, commits); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewMessage() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	m := &Message{
+		PreviousTag: "v1.1.0",
+		Tag:         "v1.2.0",
+		Version:     "1.2.0",
+		BumpType:    release.SemVerBumpTypeMinor,
+		Reason:      "passed by --bump",
+		Commits: []Commit{
+			{SHA: "1111111111", Subject: "feat: add login"},
+			{SHA: "2222222222", Subject: "fix: handle empty tags"},
+		},
+	}
+	type args struct {
+		tmpl string
+		m    *Message
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "release notes",
+			args: args{
+				tmpl: "Release {{ .Tag }} ({{ .BumpType }} since {{ .PreviousTag }})\n\n{{ range .Commits }}- {{ .Subject }} ({{ .ShortSHA }})\n{{ end }}",
+				m:    m,
+			},
+			want: "Release v1.2.0 (minor since v1.1.0)\n\n- feat: add login (1111111)\n- fix: handle empty tags (2222222)\n",
+		},
+		{
+			name:    "invalid template",
+			args:    args{tmpl: "{{ range .Commits }}", m: m},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderMessage(tt.args.tmpl, tt.args.m)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenderMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RenderMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
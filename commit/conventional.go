@@ -0,0 +1,90 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+var (
+	ErrNotConventional = fmt.Errorf("commit message is not a conventional commit")
+
+	headerRegEx = regexp.MustCompile(`^(?P<type>[a-zA-Z]+)(\((?P<scope>[^()]*)\)){0,1}(?P<breaking>!){0,1}: (?P<description>.+)$`)
+	footerRegEx = regexp.MustCompile(`^(?P<token>BREAKING CHANGE|BREAKING-CHANGE|[a-zA-Z-]+)(: | #)(?P<value>.*)$`)
+)
+
+// Conventional is a commit message parsed according to the Conventional
+// Commits specification (https://www.conventionalcommits.org).
+type Conventional struct {
+	Type        string
+	Scope       string
+	Description string
+	Body        string
+	Footers     map[string]string
+	Breaking    bool
+}
+
+// Parse parses a commit message as a conventional commit.
+// If the header does not follow the specification, ErrNotConventional is returned.
+func Parse(message string) (*Conventional, error) {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	header, rest, _ := strings.Cut(message, "\n")
+
+	match := headerRegEx.FindStringSubmatch(strings.TrimSpace(header))
+	if match == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotConventional, header)
+	}
+	cc := &Conventional{
+		Type:        strings.ToLower(match[headerRegEx.SubexpIndex("type")]),
+		Scope:       match[headerRegEx.SubexpIndex("scope")],
+		Description: match[headerRegEx.SubexpIndex("description")],
+		Breaking:    match[headerRegEx.SubexpIndex("breaking")] == "!",
+		Footers:     map[string]string{},
+	}
+
+	// the footers are located in the last paragraph of the message
+	paragraphs := strings.Split(strings.TrimSpace(rest), "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	if isFooter(last) {
+		for _, line := range strings.Split(last, "\n") {
+			fm := footerRegEx.FindStringSubmatch(line)
+			if fm == nil {
+				continue
+			}
+			cc.Footers[fm[footerRegEx.SubexpIndex("token")]] = fm[footerRegEx.SubexpIndex("value")]
+		}
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+	cc.Body = strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+
+	if _, ok := cc.Footers["BREAKING CHANGE"]; ok {
+		cc.Breaking = true
+	}
+	if _, ok := cc.Footers["BREAKING-CHANGE"]; ok {
+		cc.Breaking = true
+	}
+	return cc, nil
+}
+
+// isFooter returns true if the paragraph starts with a footer token.
+func isFooter(paragraph string) bool {
+	first, _, _ := strings.Cut(paragraph, "\n")
+	return footerRegEx.MatchString(first)
+}
+
+// BumpType returns the bump type implied by the conventional commit.
+// Breaking changes result in a major bump, features in a minor bump and
+// fixes in a patch bump. All other types do not require a bump.
+func (c *Conventional) BumpType() release.SemVerBumpType {
+	switch {
+	case c.Breaking:
+		return release.SemVerBumpTypeMajor
+	case c.Type == "feat":
+		return release.SemVerBumpTypeMinor
+	case c.Type == "fix":
+		return release.SemVerBumpTypePatch
+	}
+	return release.SemVerBumpTypeNone
+}
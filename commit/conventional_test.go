@@ -0,0 +1,146 @@
+package commit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+func TestParse(t *testing.T) {
+	type args struct {
+		message string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Conventional
+		wantErr bool
+	}{
+		{
+			name: "feat",
+			args: args{
+				message: "feat: add commit analysis",
+			},
+			want: &Conventional{
+				Type:        "feat",
+				Description: "add commit analysis",
+				Footers:     map[string]string{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "fix with scope",
+			args: args{
+				message: "fix(release): handle missing tags\n",
+			},
+			want: &Conventional{
+				Type:        "fix",
+				Scope:       "release",
+				Description: "handle missing tags",
+				Footers:     map[string]string{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "breaking with exclamation mark",
+			args: args{
+				message: "feat(api)!: drop v1 endpoints",
+			},
+			want: &Conventional{
+				Type:        "feat",
+				Scope:       "api",
+				Description: "drop v1 endpoints",
+				Footers:     map[string]string{},
+				Breaking:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "breaking change footer",
+			args: args{
+				message: "refactor: rename config keys\n\nThe keys are now lower case.\n\nBREAKING CHANGE: config files must be migrated\nRefs: #42",
+			},
+			want: &Conventional{
+				Type:        "refactor",
+				Description: "rename config keys",
+				Body:        "The keys are now lower case.",
+				Footers: map[string]string{
+					"BREAKING CHANGE": "config files must be migrated",
+					"Refs":            "#42",
+				},
+				Breaking: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "squash merge body",
+			args: args{
+				message: "fix: correct tag order (#12)\n\n* fix: sort tags\n\n* chore: cleanup",
+			},
+			want: &Conventional{
+				Type:        "fix",
+				Description: "correct tag order (#12)",
+				Body:        "* fix: sort tags\n\n* chore: cleanup",
+				Footers:     map[string]string{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "not conventional",
+			args: args{
+				message: "Merge branch 'main' into feat/abc",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.args.message)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConventional_BumpType(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Conventional
+		want release.SemVerBumpType
+	}{
+		{
+			name: "breaking",
+			c:    &Conventional{Type: "fix", Breaking: true},
+			want: release.SemVerBumpTypeMajor,
+		},
+		{
+			name: "feat",
+			c:    &Conventional{Type: "feat"},
+			want: release.SemVerBumpTypeMinor,
+		},
+		{
+			name: "fix",
+			c:    &Conventional{Type: "fix"},
+			want: release.SemVerBumpTypePatch,
+		},
+		{
+			name: "chore",
+			c:    &Conventional{Type: "chore"},
+			want: release.SemVerBumpTypeNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.BumpType(); got != tt.want {
+				t.Errorf("Conventional.BumpType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
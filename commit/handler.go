@@ -0,0 +1,111 @@
+package commit
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+// Log returns the commits reachable from head that are not reachable from base.
+// If base is the zero hash, all commits reachable from head are returned.
+// The commits are ordered from the newest to the oldest.
+func Log(repo *git.Repository, base, head plumbing.Hash) ([]*object.Commit, error) {
+	exclude := map[plumbing.Hash]struct{}{}
+	if !base.IsZero() {
+		ancestors, err := release.Ancestors(repo, base)
+		if err != nil {
+			return nil, err
+		}
+		exclude = ancestors
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: head, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	commits := []*object.Commit{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if _, ok := exclude[c.Hash]; ok {
+			return nil
+		}
+		commits = append(commits, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// Filter returns the commits that change at least one file for which
+// contains returns true. The changes of a commit are determined by comparing
+// it with its first parent.
+func Filter(commits []*object.Commit, contains func(file string) bool) ([]*object.Commit, error) {
+	filtered := []*object.Commit{}
+	for _, c := range commits {
+		files, err := changedFiles(c)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if contains(file) {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// changedFiles returns the files changed by the given commit.
+func changedFiles(c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, change := range changes {
+		if change.From.Name != "" {
+			files = append(files, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			files = append(files, change.To.Name)
+		}
+	}
+	return files, nil
+}
+
+// Identify returns the highest bump type found in the given commits.
+// Commits that do not follow the conventional commit specification are ignored.
+// If no commit requires a bump, release.SemVerBumpTypeNone is returned.
+func Identify(commits []*object.Commit) release.SemVerBumpType {
+	bumpType := release.SemVerBumpTypeNone
+	for _, c := range commits {
+		cc, err := Parse(c.Message)
+		if err != nil {
+			continue
+		}
+		if bt := cc.BumpType(); bt.Compare(bumpType) > 0 {
+			bumpType = bt
+		}
+	}
+	return bumpType
+}
@@ -0,0 +1,205 @@
+package commit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+// testRepo creates an in-memory repository with one commit per message.
+// It returns the repository and the commit hashes in the order of the messages.
+func testRepo(t *testing.T, messages ...string) (*git.Repository, []plumbing.Hash) {
+	t.Helper()
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := []plumbing.Hash{}
+	for i, msg := range messages {
+		hashes = append(hashes, testCommit(t, repo, i, "file.txt", msg))
+	}
+	return repo, hashes
+}
+
+// testCommit writes the message to the given file and commits it. The n-th
+// commit of a repository is n minutes younger than the first one, so the
+// hashes do not depend on the order of the tests.
+func testCommit(t *testing.T, repo *git.Repository, n int, file, msg string) plumbing.Hash {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := wt.Filesystem.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := wt.Add(file); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(n) * time.Minute)
+	hash, err := wt.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: when},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestLog(t *testing.T) {
+	repo, hashes := testRepo(t, "chore: init", "feat: one", "fix: two")
+	tests := []struct {
+		name    string
+		base    plumbing.Hash
+		want    []plumbing.Hash
+		wantErr bool
+	}{
+		{
+			name: "all commits",
+			base: plumbing.ZeroHash,
+			want: []plumbing.Hash{hashes[2], hashes[1], hashes[0]},
+		},
+		{
+			name: "since first commit",
+			base: hashes[0],
+			want: []plumbing.Hash{hashes[2], hashes[1]},
+		},
+		{
+			name: "since head",
+			base: hashes[2],
+			want: []plumbing.Hash{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Log(repo, tt.base, hashes[2])
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Log() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Log() returned %d commits, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Hash != tt.want[i] {
+					t.Errorf("Log()[%d] = %v, want %v", i, got[i].Hash, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := testCommit(t, repo, 0, "services/api/main.go", "feat(api): init")
+	worker := testCommit(t, repo, 1, "services/worker/main.go", "feat(worker): init")
+	readme := testCommit(t, repo, 2, "README.md", "docs: readme")
+	apiFix := testCommit(t, repo, 3, "services/api/main.go", "fix(api): bug")
+
+	commits, err := Log(repo, plumbing.ZeroHash, apiFix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		component *release.Component
+		want      []plumbing.Hash
+	}{
+		{
+			name:      "api",
+			component: &release.Component{Paths: []string{"services/api"}},
+			want:      []plumbing.Hash{apiFix, api},
+		},
+		{
+			name:      "worker",
+			component: &release.Component{Paths: []string{"services/worker/"}},
+			want:      []plumbing.Hash{worker},
+		},
+		{
+			name:      "no paths",
+			component: &release.Component{},
+			want:      []plumbing.Hash{apiFix, readme, worker, api},
+		},
+		{
+			name:      "unchanged",
+			component: &release.Component{Paths: []string{"services/web"}},
+			want:      []plumbing.Hash{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Filter(commits, tt.component.Contains)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Filter() returned %d commits, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Hash != tt.want[i] {
+					t.Errorf("Filter()[%d] = %v, want %v", i, got[i].Hash, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     release.SemVerBumpType
+	}{
+		{
+			name:     "no conventional commits",
+			messages: []string{"init", "update readme"},
+			want:     release.SemVerBumpTypeNone,
+		},
+		{
+			name:     "patch",
+			messages: []string{"chore: init", "fix: a bug"},
+			want:     release.SemVerBumpTypePatch,
+		},
+		{
+			name:     "minor",
+			messages: []string{"fix: a bug", "feat: a feature", "docs: update readme"},
+			want:     release.SemVerBumpTypeMinor,
+		},
+		{
+			name:     "major",
+			messages: []string{"feat!: breaking feature", "fix: a bug"},
+			want:     release.SemVerBumpTypeMajor,
+		},
+		{
+			name:     "major from footer",
+			messages: []string{"fix: a bug\n\nBREAKING CHANGE: removed flag"},
+			want:     release.SemVerBumpTypeMajor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, hashes := testRepo(t, tt.messages...)
+			commits, err := Log(repo, plumbing.ZeroHash, hashes[len(hashes)-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Identify(commits); got != tt.want {
+				t.Errorf("Identify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
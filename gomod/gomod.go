@@ -0,0 +1,82 @@
+package gomod
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/mod/modfile"
+)
+
+var (
+	ErrNoModule      = fmt.Errorf("go.mod does not declare a module")
+	ErrMajorMismatch = fmt.Errorf("module path does not match the major version")
+)
+
+// ModulePath returns the module path declared by the given go.mod content.
+func ModulePath(gomod []byte) (string, error) {
+	modulePath := modfile.ModulePath(gomod)
+	if modulePath == "" {
+		return "", ErrNoModule
+	}
+	return modulePath, nil
+}
+
+// Read returns the module path of the go.mod file in the given directory of
+// the commit's tree. The directory is relative to the repository root.
+func Read(c *object.Commit, dir string) (string, error) {
+	file, err := c.File(path.Join(path.Clean(dir), "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("could not read go.mod in %q: %w", dir, err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return "", err
+	}
+	return ModulePath([]byte(content))
+}
+
+// MajorVersion returns the major version declared by the suffix of the module
+// path, e.g. 2 for example.com/mod/v2. Module paths without suffix declare the
+// major version 0 or 1, for which 1 is returned.
+func MajorVersion(modulePath string) uint64 {
+	// gopkg.in uses a dot as separator, e.g. gopkg.in/yaml.v3
+	sep := "/v"
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		sep = ".v"
+	}
+	idx := strings.LastIndex(modulePath, sep)
+	if idx < 0 {
+		return 1
+	}
+	major, err := strconv.ParseUint(modulePath[idx+len(sep):], 10, 64)
+	if err != nil || major < 2 {
+		return 1
+	}
+	return major
+}
+
+// Check returns an error if the module path does not match the given major
+// version. From v2 on, Go requires the module path to end with /vN.
+func Check(modulePath string, major uint64) error {
+	if major < 2 {
+		major = 1
+	}
+	if declared := MajorVersion(modulePath); declared != major {
+		return fmt.Errorf("%w: %q declares major version %d, but the version has major version %d", ErrMajorMismatch, modulePath, declared, major)
+	}
+	return nil
+}
+
+// TagPrefix returns the tag prefix of a module in the given directory.
+// Go expects tags of nested modules to be prefixed with their directory,
+// e.g. tools/v1.2.3 for the module in the directory tools.
+func TagPrefix(dir string) string {
+	dir = strings.Trim(path.Clean(dir), "/")
+	if dir == "." || dir == "" {
+		return ""
+	}
+	return dir + "/"
+}
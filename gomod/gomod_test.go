@@ -0,0 +1,235 @@
+package gomod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		gomod   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "module",
+			gomod: "module github.com/leonsteinhaeuser/git-tag-bump\n\ngo 1.23.0\n",
+			want:  "github.com/leonsteinhaeuser/git-tag-bump",
+		},
+		{
+			name:  "comments and quotes",
+			gomod: "// Deprecated: use v2\nmodule \"example.com/mod/v2\" // v2\n",
+			want:  "example.com/mod/v2",
+		},
+		{
+			name:    "no module",
+			gomod:   "go 1.23.0\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ModulePath([]byte(tt.gomod))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ModulePath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ModulePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":       "module example.com/mod\n",
+		"tools/go.mod": "module example.com/mod/tools/v3\n",
+	}
+	for name, content := range files {
+		f, err := wt.Filesystem.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+		f.Close()
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := wt.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "root",
+			dir:  ".",
+			want: "example.com/mod",
+		},
+		{
+			name: "nested",
+			dir:  "tools/",
+			want: "example.com/mod/tools/v3",
+		},
+		{
+			name:    "missing",
+			dir:     "docs",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(c, tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Read() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		modulePath string
+		want       uint64
+	}{
+		{
+			name:       "no suffix",
+			modulePath: "example.com/mod",
+			want:       1,
+		},
+		{
+			name:       "v2",
+			modulePath: "example.com/mod/v2",
+			want:       2,
+		},
+		{
+			name:       "v in the middle",
+			modulePath: "example.com/v2/mod",
+			want:       1,
+		},
+		{
+			name:       "path starting with v",
+			modulePath: "example.com/vendor",
+			want:       1,
+		},
+		{
+			name:       "gopkg.in",
+			modulePath: "gopkg.in/yaml.v3",
+			want:       3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MajorVersion(tt.modulePath); got != tt.want {
+				t.Errorf("MajorVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		modulePath string
+		major      uint64
+		wantErr    bool
+	}{
+		{
+			name:       "v0",
+			modulePath: "example.com/mod",
+			major:      0,
+		},
+		{
+			name:       "v1",
+			modulePath: "example.com/mod",
+			major:      1,
+		},
+		{
+			name:       "v2 without suffix",
+			modulePath: "example.com/mod",
+			major:      2,
+			wantErr:    true,
+		},
+		{
+			name:       "v2 with suffix",
+			modulePath: "example.com/mod/v2",
+			major:      2,
+		},
+		{
+			name:       "v3 with v2 suffix",
+			modulePath: "example.com/mod/v2",
+			major:      3,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.modulePath, tt.major); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{
+			name: "root",
+			dir:  ".",
+			want: "",
+		},
+		{
+			name: "empty",
+			dir:  "",
+			want: "",
+		},
+		{
+			name: "nested",
+			dir:  "./tools/cli/",
+			want: "tools/cli/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TagPrefix(tt.dir); got != tt.want {
+				t.Errorf("TagPrefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
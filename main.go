@@ -1,165 +1,385 @@
 package main
 
 import (
+	"context"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
 	"time"
 
-	"github.com/Masterminds/semver/v3"
-	"github.com/go-git/go-git/v5"
-	gconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/leonsteinhaeuser/git-tag-bump/auth"
 	"github.com/leonsteinhaeuser/git-tag-bump/branch"
+	"github.com/leonsteinhaeuser/git-tag-bump/bump"
+	"github.com/leonsteinhaeuser/git-tag-bump/changelog"
 	"github.com/leonsteinhaeuser/git-tag-bump/release"
+	"github.com/leonsteinhaeuser/git-tag-bump/sign"
 	"gopkg.in/yaml.v3"
 )
 
 var (
+	versionScheme        = flag.String("scheme", "semver", "Version scheme. Can be 'semver' or 'calver'")
+	calVerFormat         = flag.String("calver-format", "YYYY.MM.MICRO", "Format of the calendar versions, e.g. 'YYYY.MM.MICRO' or 'YY.0M.MICRO'. Only used if --scheme is 'calver'.")
 	preReleaseFormat     = flag.String("pre-release-format", release.PreReleaseFormatSemVer.String(), "Prerelease format. Can be 'semver', 'date' or 'datetime'")
 	preReleasePrefix     = flag.String("pre-release-prefix", "rc", "Prerelease prefix")
+	metadataFormat       = flag.String("metadata-format", "", "Build metadata added to the version. Can be 'sha', 'build' or 'timestamp'. If not set, no metadata is added.")
+	metadataBuildEnv     = flag.String("metadata-build-env", "GITHUB_RUN_NUMBER", "Environment variable holding the build number. Only used if --metadata-format is 'build'.")
+	tagMetadata          = flag.Bool("tag-metadata", true, "Whether to include the build metadata in the tag name. If false, the metadata is only part of the output.")
+	preReleaseChannels   = flag.String("pre-release-channels", "", "Comma separated, ordered list of pre-release channels, e.g. 'alpha,beta,rc'. A pre-release can only move to the same or a later channel.")
 	bumpType             = flag.String("bump", release.SemVerBumpTypePatch.String(), "Bump type (major, minor, patch, none)")
 	isPreRelease         = flag.Bool("pre-release", false, "Whether to create a pre-release")
+	preReleaseAware      = flag.Bool("pre-release-aware", false, "Whether to only increment the pre-release counter if the pending pre-release already covers the requested bump")
 	repoTarget           = flag.String("repo-path", ".", "Path to the repository")
+	repoURL              = flag.String("repo-url", "", "URL of a remote repository to read instead of a local checkout. Only its tags are listed, unless commits are analyzed or a tag is created, which clones it into memory.")
 	configPath           = flag.String("config", "", "Path to the config file")
 	autoBump             = flag.Bool("auto-bump", false, "Whether to automatically bump the version based on the rules in the config file")
+	conventionalCommits  = flag.Bool("conventional-commits", false, "Whether to determine the bump type from the Conventional Commit messages since the latest tag")
 	createTag            = flag.Bool("create", false, "Whether to create a tag in the repository and push it to the remote")
+	forceTag             = flag.Bool("force-tag", false, "Whether to create a new tag even if the commit already has a tag of the version scheme. Otherwise, the existing tag is printed.")
+	pushExisting         = flag.Bool("push-existing", false, "Whether to push the existing tag of the commit to the remotes again. Only used if --create is set.")
+	changelogPath        = flag.String("changelog", "", "Path of the changelog file relative to the repository root, e.g. 'CHANGELOG.md'. If set, a section with the commits since the previous tag is prepended to it.")
+	changelogTemplate    = flag.String("changelog-template", "", "Path of a text/template file used to render the changelog section. Defaults to a Keep a Changelog style template.")
+	tagMessageTemplate   = flag.String("tag-message-template", "", "Path of a text/template file used to render the message of annotated tags. If not set, the message is the tag name.")
+	outputFormat         = flag.String("output", "text", "Output format. Can be 'text' to print the tag, or 'json' to print the computed release as JSON document.")
+	quiet                = flag.Bool("quiet", false, "Whether to only log errors. Results are always printed to stdout, logs to stderr.")
+	verbose              = flag.Bool("verbose", false, "Whether to log debug messages. Results are always printed to stdout, logs to stderr.")
+	explain              = flag.Bool("explain", false, "Whether to print the decision trace that led to the new tag. Implies a dry run, so no tag is fetched, created or pushed.")
 	createTagLightweight = flag.Bool("lightweight", false, "Whether any tag created should be a lightweight tag")
 	branchName           = flag.String("branch-name", "", "Name of the branch to check")
 	vPrefix              = flag.Bool("v-prefix", true, "Whether to prefix the tag with a 'v'. E.g. v1.0.0 instead of 1.0.0")
 	gitBaseTagOverride   = flag.String("git-base-tag", "", "Override the base tag to use for the bump. If not set, the latest tag will be used.")
+	promote              = flag.Bool("promote", false, "Whether to promote the latest pre-release, or the one passed by --git-base-tag, to a stable release")
+	promoteCommit        = flag.Bool("promote-commit", false, "Whether to tag the commit of the promoted pre-release instead of HEAD. Only used if --promote is set.")
+	componentName        = flag.String("component", "", "Name of the component defined in the config file. Scopes the tags and commits to the component.")
+	tagPrefix            = flag.String("tag-prefix", "", "Prefix of the tags to consider and create, e.g. 'services/api/'. Ignored if --component is set.")
+	goModule             = flag.String("go-module", "", "Whether to check the module path of go.mod against the major version of the new tag. Can be 'warn' or 'error'. If not set, go.mod is not read.")
+	goModuleDir          = flag.String("go-module-dir", "", "Directory of the Go module relative to the repository root. Defaults to the first path of the component or the repository root.")
+	goModuleTags         = flag.Bool("go-module-tags", false, "Whether to prefix the tags with the directory of the Go module, e.g. 'tools/v1.2.3' for the nested module in 'tools'")
+	fetchTags            = flag.Bool("fetch-tags", false, "Whether to fetch the tags of the primary remote before the latest tag is determined")
+	shallow              = flag.String("shallow", bump.ShallowFail.String(), "How to handle a shallow clone if --fetch-tags is set. Can be 'fail' or 'deepen' to fetch the full history.")
+	reachableOnly        = flag.Bool("reachable-only", false, "Whether to only consider tags that are reachable from --reachable-from, similar to git describe")
+	reachableFrom        = flag.String("reachable-from", "", "The revision (branch, tag or commit) from which the tags must be reachable. Defaults to --target or HEAD. Only used if --reachable-only is set.")
+	target               = flag.String("target", "", "The revision (commit, branch or tag) that is analyzed and tagged instead of HEAD. A branch is also used for the branch rules, unless --branch-name is set.")
+
+	signFormat        = flag.String("sign-format", "", "Format of the tag signature. Can be 'openpgp' or 'ssh'. If not set, tags are not signed. Only used if --create is set.")
+	signKey           = flag.String("sign-key", "", "Path of the private key used to sign the tag, an armored OpenPGP key or an SSH key")
+	signKeyEnv        = flag.String("sign-key-env", "", "Environment variable holding the private key used to sign the tag. Used instead of --sign-key.")
+	signPassphraseEnv = flag.String("sign-passphrase-env", "", "Environment variable holding the passphrase of an encrypted signing key")
+
+	retries             = flag.Int("retries", 3, "How often the version is recomputed if another job pushed the tag for another commit first")
+	atomic              = flag.Bool("atomic", false, "Whether to delete the tag from all remotes again if the push to any remote fails. Otherwise, only a failed push to the first remote fails.")
+	tokenEnv            = flag.String("token-env", "GITHUB_TOKEN", "Environment variable holding the access token used to push to HTTP(S) remotes")
+	tokenUsername       = flag.String("token-username", auth.DefaultTokenUsername, "Username sent with the access token, e.g. 'oauth2' for GitLab")
+	sshKey              = flag.String("ssh-key", "", "Path of the private key used to push to SSH remotes. If not set, the SSH agent is used.")
+	sshKeyPassphraseEnv = flag.String("ssh-key-passphrase-env", "", "Environment variable holding the passphrase of an encrypted SSH key")
+	netrc               = flag.Bool("netrc", true, "Whether to read the credentials of HTTP(S) remotes from $NETRC or ~/.netrc")
+	credentialHelper    = flag.Bool("credential-helper", false, "Whether to ask the credential helpers configured in git for the credentials of HTTP(S) remotes")
 
 	actorName = flag.String("actor-name", "", "The name of the actor used to create the tag. Only used if --create is set.")
 	actorMail = flag.String("actor-mail", "", "The mail of the actor used to create the tag. Only used if --create is set.")
 
-	actor *object.Signature = &object.Signature{}
-
-	githubToken = os.Getenv("GITHUB_TOKEN")
+	remotes stringsFlag
 
 	//go:embed config.yaml
 	configBts []byte
-	// embed default config during build
-	config *branch.Config
+
+	// errUsage is returned for invalid flags and config files
+	errUsage = errors.New("invalid usage")
 )
 
+// exit codes of the process, each class of errors has its own code
+const (
+	exitOK              = 0
+	exitError           = 1
+	exitUsage           = 2
+	exitNoReleaseNeeded = 3
+	exitNoMatchingRule  = 4
+	exitNoTags          = 5
+	exitTagExists       = 6
+	exitPushRejected    = 7
+	exitAuthMissing     = 8
+	exitSignature       = 9
+	exitShallow         = 10
+	exitTagConflict     = 11
+)
+
+// stringsFlag is a flag that can be passed multiple times.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func init() {
+	flag.Var(&remotes, "remote", "Name or URL of a remote the tag is pushed to. Can be passed multiple times, the first one is the primary remote. Defaults to 'origin'.")
+}
+
+func main() {
 	flag.Parse()
-	// if config flag is set, read config from file
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx)
+	stop()
+	switch {
+	case err == nil:
+	case errors.Is(err, release.ErrNoReleaseNeeded):
+		slog.Info(err.Error())
+	default:
+		slog.Error(err.Error())
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the exit code of the process for the given error.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage), errors.Is(err, bump.ErrInvalidOptions), errors.Is(err, changelog.ErrTemplate),
+		errors.Is(err, sign.ErrFormat), errors.Is(err, sign.ErrKey), errors.Is(err, release.ErrPreRelease):
+		return exitUsage
+	case errors.Is(err, release.ErrNoReleaseNeeded):
+		return exitNoReleaseNeeded
+	case errors.Is(err, branch.ErrNoMatchingRule):
+		return exitNoMatchingRule
+	case errors.Is(err, release.ErrNoTags):
+		return exitNoTags
+	case errors.Is(err, release.ErrTagExists):
+		return exitTagExists
+	case errors.Is(err, release.ErrAuthMissing):
+		return exitAuthMissing
+	case errors.Is(err, release.ErrPushRejected):
+		return exitPushRejected
+	case errors.Is(err, sign.ErrSignature):
+		return exitSignature
+	case errors.Is(err, release.ErrShallow):
+		return exitShallow
+	case errors.Is(err, release.ErrTagConflict):
+		return exitTagConflict
+	}
+	return exitError
+}
+
+// run maps the flags to the options of the bumper, runs it and prints the
+// result. If no release is needed, the latest tag is printed and
+// release.ErrNoReleaseNeeded is returned. If a push fails, the result is only
+// printed as JSON document, together with the error.
+func run(ctx context.Context) error {
+	opts, err := options()
+	if err != nil {
+		return err
+	}
+	bumper, err := bump.New(opts)
+	if err != nil {
+		return err
+	}
+	result, err := bumper.Run(ctx)
+	// the JSON document of a failed push reports the result of each remote
+	if result != nil && (err == nil || errors.Is(err, release.ErrNoReleaseNeeded) || (*outputFormat == "json" && len(result.Remotes) > 0)) {
+		if printErr := printResult(result); printErr != nil && err == nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+// options sets up the logger and returns the options of the flags.
+func options() (bump.Options, error) {
+	opts := bump.DefaultOptions()
+
+	// stdout is reserved for the result, so diagnostics are logged to stderr
+	level := slog.LevelInfo
+	switch {
+	case *quiet && *verbose:
+		return opts, fmt.Errorf("%w: only one of --quiet and --verbose can be set", errUsage)
+	case *quiet:
+		level = slog.LevelError
+	case *verbose:
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	// if config flag is set, read config from file, otherwise use the embedded default config
 	if *configPath != "" {
 		cfg, err := branch.ReadConfig(*configPath)
 		if err != nil {
-			panic(err)
+			return opts, fmt.Errorf("%w: could not read config %q: %w", errUsage, *configPath, err)
 		}
-		config = cfg
+		opts.Config = cfg
 	} else {
-		err := yaml.Unmarshal(configBts, &config)
+		err := yaml.Unmarshal(configBts, &opts.Config)
 		if err != nil {
-			panic(err)
+			return opts, err
 		}
 	}
 
-	if *createTag && !*createTagLightweight && (*actorName == "" || *actorMail == "" || githubToken == "") {
-		panic("Either --lightweight, or both --actor-name and --actor-mail must be set when --create is set")
+	if *createTag && !*createTagLightweight && (*actorName == "" || *actorMail == "") {
+		return opts, fmt.Errorf("%w: either --lightweight, or both --actor-name and --actor-mail must be set when --create is set", errUsage)
 	}
-
-	if *createTag && *actorName != "" && *actorMail != "" {
-		actor.Email = *actorMail
-		actor.Name = *actorName
-		actor.When = time.Now()
-	}
-}
-
-func main() {
-	repo, err := git.PlainOpen(*repoTarget)
-	if err != nil {
-		panic(err)
+	if *outputFormat != "text" && *outputFormat != "json" {
+		return opts, fmt.Errorf("%w: unknown output format %q, must be 'text' or 'json'", errUsage, *outputFormat)
 	}
 
-	latest, err := release.GetLatestSemVerTagFromRepo(repo, *isPreRelease)
+	scheme, err := release.ParseScheme(*versionScheme, *calVerFormat)
 	if err != nil {
-		panic(err)
+		return opts, fmt.Errorf("%w: %w", errUsage, err)
 	}
 
-	// override the current latest identified tag with the one from the flag
-	if *gitBaseTagOverride != "" {
-		overrideTag := semver.MustParse(*gitBaseTagOverride)
-		if overrideTag.Major() != latest.Major() || overrideTag.Minor() != latest.Minor() || overrideTag.Patch() != latest.Patch() {
-			// if the major, minor or patch version of the override tag does not match the latest tag, use the override tag
-			latest = overrideTag
+	if *changelogTemplate != "" {
+		tmpl, err := os.ReadFile(*changelogTemplate)
+		if err != nil {
+			return opts, fmt.Errorf("%w: could not read changelog template %q: %w", errUsage, *changelogTemplate, err)
 		}
+		opts.ChangelogTemplate = string(tmpl)
 	}
 
-	bt := release.SemVerBumpType(*bumpType)
-	if *autoBump && *branchName == "" {
-		identifier, err := branch.Identify(config, repo)
+	if *tagMessageTemplate != "" {
+		tmpl, err := os.ReadFile(*tagMessageTemplate)
 		if err != nil {
-			panic(err)
+			return opts, fmt.Errorf("%w: could not read tag message template %q: %w", errUsage, *tagMessageTemplate, err)
 		}
-		bt = identifier
+		opts.TagMessageTemplate = string(tmpl)
 	}
-	if *branchName != "" {
-		smvTag, err := branch.IdentifyBranch(config, *branchName)
+
+	if *createTag && *signFormat != "" {
+		signer, err := signer()
 		if err != nil {
-			panic(err)
+			return opts, err
 		}
-		bt = smvTag
+		opts.Signer = signer
 	}
 
-	newTag := release.BumpTag(
-		latest,
-		bt,
-		release.PreReleaseFormat(*preReleaseFormat),
-		*preReleasePrefix,
-		*isPreRelease,
-	)
-
-	// add v prefix if enabled
-	if *vPrefix {
-		newTag = fmt.Sprintf("v%s", newTag)
+	opts.RepoPath = *repoTarget
+	opts.RepoURL = *repoURL
+	opts.Scheme = scheme
+	opts.Component = *componentName
+	opts.TagPrefix = *tagPrefix
+	opts.Target = *target
+	if *reachableOnly {
+		opts.ReachableFrom = *reachableFrom
+		switch {
+		case opts.ReachableFrom == "" && *target != "":
+			opts.ReachableFrom = *target
+		case opts.ReachableFrom == "":
+			opts.ReachableFrom = "HEAD"
+		}
 	}
+	opts.BaseTag = *gitBaseTagOverride
+	opts.FetchTags = *fetchTags
+	opts.Shallow = bump.ShallowMode(*shallow)
+	opts.BumpType = release.SemVerBumpType(*bumpType)
+	opts.BranchName = *branchName
+	opts.ConventionalCommits = *conventionalCommits
+	opts.AutoBump = *autoBump
+	opts.PreRelease = *isPreRelease
+	opts.PreReleaseFormat = release.PreReleaseFormat(*preReleaseFormat)
+	opts.PreReleasePrefix = *preReleasePrefix
+	opts.PreReleaseAware = *preReleaseAware
+	opts.Channels = release.ParseChannels(*preReleaseChannels)
+	opts.MetadataFormat = release.MetadataFormat(*metadataFormat)
+	opts.BuildNumber = os.Getenv(*metadataBuildEnv)
+	opts.StripTagMetadata = !*tagMetadata
+	opts.VPrefix = *vPrefix
+	opts.Promote = *promote
+	opts.PromoteCommit = *promoteCommit
+	opts.GoModule = bump.GoModuleCheck(*goModule)
+	opts.GoModuleDir = *goModuleDir
+	opts.GoModuleTags = *goModuleTags
+	opts.Create = *createTag
+	opts.Lightweight = *createTagLightweight
+	opts.ForceTag = *forceTag
+	opts.PushExisting = *pushExisting
+	opts.Remotes = remotes
+	opts.Atomic = *atomic
+	opts.Retries = *retries
+	opts.Changelog = *changelogPath
+	opts.Explain = *explain
 
-	if *createTag {
-		rfc, err := repo.Head()
-		if err != nil {
-			panic(err)
-		}
+	if *createTag && *actorName != "" && *actorMail != "" {
+		opts.Tagger = &object.Signature{Name: *actorName, Email: *actorMail, When: time.Now()}
+	}
+	opts.AuthProvider = authProvider()
+	// the push progress is a diagnostic and must not mix with the result on stdout
+	if !*quiet {
+		opts.Progress = os.Stderr
+	}
+	return opts, nil
+}
 
-		var options *git.CreateTagOptions
-		if !*createTagLightweight {
-			options = &git.CreateTagOptions{
-				//Tagger:  nil,
-				Message: newTag,
-				Tagger: func() *object.Signature {
-					if actor.Name == "" || actor.Email == "" {
-						return nil
-					}
-					return actor
-				}(),
-			}
-		}
+// authProvider returns the providers of the push credentials in the order
+// they are asked: the access token, the SSH key or agent, the netrc file and
+// the credential helpers. The first provider with credentials for the URL
+// scheme of the remote is used.
+func authProvider() auth.Provider {
+	providers := auth.Providers{
+		&auth.Token{Username: *tokenUsername, Token: os.Getenv(*tokenEnv)},
+	}
+	if *sshKey != "" {
+		providers = append(providers, &auth.SSHKey{Path: *sshKey, Passphrase: os.Getenv(*sshKeyPassphraseEnv)})
+	} else {
+		providers = append(providers, &auth.SSHAgent{})
+	}
+	if *netrc {
+		providers = append(providers, &auth.Netrc{})
+	}
+	if *credentialHelper {
+		providers = append(providers, &auth.CredentialHelper{Dir: *repoTarget})
+	}
+	return providers
+}
 
-		// create the tag in the repository for the current commit hash
-		pmbrfc, err := repo.CreateTag(newTag, rfc.Hash(), options)
+// signer returns the signer of the format passed by --sign-format. The key
+// is read from the file passed by --sign-key or the environment variable
+// passed by --sign-key-env.
+func signer() (sign.Signer, error) {
+	var key []byte
+	switch {
+	case *signKey != "" && *signKeyEnv != "":
+		return nil, fmt.Errorf("%w: only one of --sign-key and --sign-key-env can be set", errUsage)
+	case *signKey != "":
+		var err error
+		key, err = os.ReadFile(*signKey)
 		if err != nil {
-			panic(fmt.Sprintf("Could not create tag: %q, exited with error: %s", newTag, err))
+			return nil, fmt.Errorf("%w: could not read signing key %q: %w", errUsage, *signKey, err)
 		}
-
-		// push the tag to the remote
-		refTag := pmbrfc.Name().String()
-		err = repo.Push(&git.PushOptions{
-			FollowTags: true,
-			RefSpecs: []gconfig.RefSpec{
-				gconfig.RefSpec(fmt.Sprintf("%s:%s", refTag, refTag)),
-			},
-			Progress: os.Stdout,
-			Auth:     &http.BasicAuth{Username: "bot", Password: githubToken},
-		})
-		if err != nil {
-			panic(err)
+	case *signKeyEnv != "":
+		key = []byte(os.Getenv(*signKeyEnv))
+		if len(key) == 0 {
+			return nil, fmt.Errorf("%w: the environment variable %q of the signing key is empty", errUsage, *signKeyEnv)
 		}
+	default:
+		return nil, fmt.Errorf("%w: either --sign-key or --sign-key-env must be set when --sign-format is set", errUsage)
+	}
+	var passphrase []byte
+	if *signPassphraseEnv != "" {
+		passphrase = []byte(os.Getenv(*signPassphraseEnv))
 	}
+	return sign.New(sign.Format(*signFormat), key, passphrase)
+}
 
-	fmt.Println(newTag)
+// printResult prints the result in the format passed by --output to stdout,
+// which is reserved for the result. The text format prints the decision trace
+// if --explain is set, followed by the tag.
+func printResult(result *release.Result) error {
+	if *outputFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	// stdout only holds the tag, so it can be captured by scripts
+	for _, step := range result.Explain {
+		fmt.Fprintln(os.Stderr, "-", step)
+	}
+	fmt.Println(result.Tag)
+	return nil
 }
@@ -0,0 +1,50 @@
+package release
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnknownChannel   = fmt.Errorf("pre-release channel is not configured")
+	ErrChannelDowngrade = fmt.Errorf("pre-release channel can not be downgraded")
+)
+
+// Channels is the ordered list of pre-release channels, e.g. alpha, beta, rc.
+// Every channel has its own counter and a pre-release can only move to the
+// same or a later channel of the same version.
+type Channels []string
+
+// ParseChannels parses a comma separated list of pre-release channels.
+func ParseChannels(value string) Channels {
+	channels := Channels{}
+	for _, channel := range strings.Split(value, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// Check returns an error if a pre-release can not move from one channel to
+// another. If no channels are configured, every move is allowed.
+func (c Channels) Check(from, to string) error {
+	if len(c) == 0 {
+		return nil
+	}
+	toIdx := slices.Index(c, to)
+	if toIdx < 0 {
+		return fmt.Errorf("%w: %q is not one of %v", ErrUnknownChannel, to, []string(c))
+	}
+	if fromIdx := slices.Index(c, from); fromIdx > toIdx {
+		return fmt.Errorf("%w: from %q to %q", ErrChannelDowngrade, from, to)
+	}
+	return nil
+}
+
+// preReleaseChannel returns the channel of a pre-release, e.g. beta for beta.3.
+func preReleaseChannel(preRelease string) string {
+	channel, _, _ := strings.Cut(preRelease, ".")
+	return channel
+}
@@ -0,0 +1,93 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChannels(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  Channels
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  Channels{},
+		},
+		{
+			name:  "ordered channels",
+			value: "alpha, beta,rc",
+			want:  Channels{"alpha", "beta", "rc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseChannels(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChannels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannels_Check(t *testing.T) {
+	channels := Channels{"alpha", "beta", "rc"}
+	tests := []struct {
+		name     string
+		channels Channels
+		from     string
+		to       string
+		wantErr  bool
+	}{
+		{
+			name:     "no channels configured",
+			channels: nil,
+			from:     "rc",
+			to:       "alpha",
+			wantErr:  false,
+		},
+		{
+			name:     "new pre-release",
+			channels: channels,
+			from:     "",
+			to:       "beta",
+			wantErr:  false,
+		},
+		{
+			name:     "same channel",
+			channels: channels,
+			from:     "beta",
+			to:       "beta",
+			wantErr:  false,
+		},
+		{
+			name:     "move up",
+			channels: channels,
+			from:     "beta",
+			to:       "rc",
+			wantErr:  false,
+		},
+		{
+			name:     "move down",
+			channels: channels,
+			from:     "rc",
+			to:       "alpha",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown channel",
+			channels: channels,
+			from:     "beta",
+			to:       "nightly",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.channels.Check(tt.from, tt.to); (err != nil) != tt.wantErr {
+				t.Errorf("Channels.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
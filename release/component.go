@@ -0,0 +1,36 @@
+package release
+
+import (
+	"path"
+	"strings"
+)
+
+// Component is a part of a repository that is versioned independently,
+// e.g. a service in a monorepo. Its tags are prefixed with TagPrefix and only
+// changes to files below Paths result in a new version.
+type Component struct {
+	Name      string   `yaml:"name"`
+	TagPrefix string   `yaml:"tagPrefix"`
+	Paths     []string `yaml:"paths"`
+}
+
+// TagName returns the name of the tag for the given version.
+func (c *Component) TagName(version string) string {
+	return c.TagPrefix + version
+}
+
+// Contains returns true if the given file belongs to the component.
+// A component without paths contains all files of the repository.
+func (c *Component) Contains(file string) bool {
+	if len(c.Paths) == 0 {
+		return true
+	}
+	file = path.Clean(file)
+	for _, p := range c.Paths {
+		p = path.Clean(p)
+		if p == "." || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
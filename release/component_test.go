@@ -0,0 +1,64 @@
+package release
+
+import "testing"
+
+func TestComponent_TagName(t *testing.T) {
+	c := &Component{Name: "api", TagPrefix: "services/api/"}
+	if got := c.TagName("v1.4.2"); got != "services/api/v1.4.2" {
+		t.Errorf("Component.TagName() = %v, want %v", got, "services/api/v1.4.2")
+	}
+}
+
+func TestComponent_Contains(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		file  string
+		want  bool
+	}{
+		{
+			name:  "no paths",
+			paths: nil,
+			file:  "README.md",
+			want:  true,
+		},
+		{
+			name:  "file below path",
+			paths: []string{"services/api"},
+			file:  "services/api/main.go",
+			want:  true,
+		},
+		{
+			name:  "path with trailing slash",
+			paths: []string{"services/api/"},
+			file:  "services/api/cmd/main.go",
+			want:  true,
+		},
+		{
+			name:  "exact file",
+			paths: []string{"go.mod"},
+			file:  "go.mod",
+			want:  true,
+		},
+		{
+			name:  "path with same prefix",
+			paths: []string{"services/api"},
+			file:  "services/api-gateway/main.go",
+			want:  false,
+		},
+		{
+			name:  "other component",
+			paths: []string{"services/api", "libs/api"},
+			file:  "services/worker/main.go",
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Component{Paths: tt.paths}
+			if got := c.Contains(tt.file); got != tt.want {
+				t.Errorf("Component.Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
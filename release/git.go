@@ -0,0 +1,343 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+var (
+	ErrTagExists    = fmt.Errorf("tag already exists")
+	ErrPushRejected = fmt.Errorf("push was rejected by the remote")
+	ErrAuthMissing  = fmt.Errorf("authentication for the remote is missing or invalid")
+	ErrShallow      = fmt.Errorf("repository is a shallow clone")
+	ErrTagConflict  = fmt.Errorf("tag exists on the remote for another commit")
+)
+
+// deepenDepth is the depth of a fetch that deepens a shallow clone to its full
+// history, like git fetch --unshallow.
+const deepenDepth = 2147483647
+
+// PushError describes a tag the remote refused to accept.
+// It matches ErrPushRejected and the error returned by the remote.
+type PushError struct {
+	Remote string
+	Tag    string
+	Err    error
+}
+
+func (e *PushError) Error() string {
+	return fmt.Sprintf("%s: tag %q to %q: %s", ErrPushRejected, e.Tag, e.Remote, e.Err)
+}
+
+func (e *PushError) Unwrap() []error {
+	return []error{ErrPushRejected, e.Err}
+}
+
+// ResolveTag returns the hash of the commit the given tag points to.
+// Annotated tags are peeled to the commit they reference.
+func ResolveTag(repo *git.Repository, name string) (plumbing.Hash, error) {
+	ref, err := repo.Tag(name)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return peelTag(repo, ref)
+}
+
+// peelTag returns the commit hash of a tag reference. Lightweight tags point
+// to the commit directly, annotated tags point to a tag object.
+func peelTag(repo *git.Repository, ref *plumbing.Reference) (plumbing.Hash, error) {
+	tagObj, err := repo.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		cmt, err := tagObj.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return cmt.Hash, nil
+	case plumbing.ErrObjectNotFound:
+		return ref.Hash(), nil
+	}
+	return plumbing.ZeroHash, err
+}
+
+// Ancestors returns the set of commits reachable from the given commit,
+// including the commit itself.
+func Ancestors(repo *git.Repository, hash plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	iter, err := repo.Log(&git.LogOptions{From: hash})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	ancestors := map[plumbing.Hash]struct{}{}
+	err = iter.ForEach(func(c *object.Commit) error {
+		ancestors[c.Hash] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ancestors, nil
+}
+
+// CreateTag creates the tag for the target commit. If options is nil, a
+// lightweight tag is created. If the tag exists, ErrTagExists is returned.
+func CreateTag(repo *git.Repository, name string, target plumbing.Hash, options *git.CreateTagOptions) (*plumbing.Reference, error) {
+	ref, err := repo.CreateTag(name, target, options)
+	if errors.Is(err, git.ErrTagExists) {
+		return nil, fmt.Errorf("%w: %q", ErrTagExists, name)
+	}
+	return ref, err
+}
+
+// Remote returns the configured remote of the given name. A URL or path that
+// is no configured remote is returned as anonymous remote.
+func Remote(repo *git.Repository, nameOrURL string) (*git.Remote, error) {
+	remote, err := repo.Remote(nameOrURL)
+	if !errors.Is(err, git.ErrRemoteNotFound) {
+		return remote, err
+	}
+	// a plain name without separators is a typo of a remote, not a path
+	if !strings.ContainsAny(nameOrURL, ":/") {
+		return nil, fmt.Errorf("%w: %q", err, nameOrURL)
+	}
+	return git.NewRemote(repo.Storer, &gconfig.RemoteConfig{Name: "anonymous", URLs: []string{nameOrURL}}), nil
+}
+
+// PushTag pushes the tag to the given remote name or URL. If the remote
+// requires other credentials, ErrAuthMissing is returned. All other refusals
+// are returned as *PushError.
+func PushTag(ctx context.Context, repo *git.Repository, remote string, tag *plumbing.Reference, auth transport.AuthMethod, progress io.Writer) error {
+	refTag := tag.Name().String()
+	return push(ctx, repo, remote, tag.Name().Short(), &git.PushOptions{
+		FollowTags: true,
+		RefSpecs: []gconfig.RefSpec{
+			gconfig.RefSpec(fmt.Sprintf("%s:%s", refTag, refTag)),
+		},
+		Progress: progress,
+		Auth:     auth,
+	})
+}
+
+// DeleteRemoteTag deletes the tag from the given remote name or URL. Errors
+// are returned like by PushTag.
+func DeleteRemoteTag(ctx context.Context, repo *git.Repository, remote string, tag *plumbing.Reference, auth transport.AuthMethod, progress io.Writer) error {
+	return push(ctx, repo, remote, tag.Name().Short(), &git.PushOptions{
+		RefSpecs: []gconfig.RefSpec{
+			gconfig.RefSpec(":" + tag.Name().String()),
+		},
+		Progress: progress,
+		Auth:     auth,
+	})
+}
+
+// IsShallow returns true if the repository is a shallow clone, i.e. it lacks
+// the history before some commits.
+func IsShallow(repo *git.Repository) (bool, error) {
+	shallows, err := repo.Storer.Shallow()
+	if err != nil {
+		return false, err
+	}
+	return len(shallows) > 0, nil
+}
+
+// FetchTags fetches the tags of the given remote name or URL. Local tags that
+// differ from the remote are overwritten by the remote ones. If deepen is
+// set, the full history of the tags and of the fetched branches of the remote
+// is fetched, which converts a shallow clone to a complete one. If the clone
+// stays shallow, ErrShallow is returned.
+func FetchTags(ctx context.Context, repo *git.Repository, remote string, deepen bool, auth transport.AuthMethod, progress io.Writer) error {
+	r, err := Remote(repo, remote)
+	if err != nil {
+		return err
+	}
+	options := &git.FetchOptions{
+		RemoteName: r.Config().Name,
+		RefSpecs:   []gconfig.RefSpec{"refs/tags/*:refs/tags/*"},
+		Tags:       git.AllTags,
+		Auth:       auth,
+		Progress:   progress,
+	}
+	if deepen {
+		options.Depth = deepenDepth
+		options.RefSpecs = append(options.RefSpecs, r.Config().Fetch...)
+	}
+	err = r.FetchContext(ctx, options)
+	switch {
+	case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		return fmt.Errorf("%w: %s", ErrAuthMissing, err)
+	default:
+		return fmt.Errorf("could not fetch the tags of %q: %w", remote, err)
+	}
+	if !deepen {
+		return nil
+	}
+
+	remaining, err := pruneShallow(repo)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return fmt.Errorf("%w: %d commits still lack their history after deepening", ErrShallow, remaining)
+	}
+	return nil
+}
+
+// pruneShallow removes the commits whose parents are present from the
+// shallow commits of the repository, as go-git only adds shallow commits. It
+// returns the number of remaining shallow commits.
+func pruneShallow(repo *git.Repository) (int, error) {
+	shallows, err := repo.Storer.Shallow()
+	if err != nil {
+		return 0, err
+	}
+	remaining := []plumbing.Hash{}
+	for _, hash := range shallows {
+		c, err := repo.CommitObject(hash)
+		if err != nil {
+			return 0, err
+		}
+		for _, parent := range c.ParentHashes {
+			if _, err := repo.Storer.EncodedObject(plumbing.CommitObject, parent); err != nil {
+				remaining = append(remaining, hash)
+				break
+			}
+		}
+	}
+	if err := repo.Storer.SetShallow(remaining); err != nil {
+		return 0, err
+	}
+	return len(remaining), nil
+}
+
+// ListRemote returns an in-memory repository with the tags, the branches and
+// HEAD of the remote URL, like git ls-remote. The repository has no objects,
+// so annotated tags point to their commit directly.
+func ListRemote(ctx context.Context, url string, auth transport.AuthMethod) (*git.Repository, error) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}})
+	if err != nil {
+		return nil, err
+	}
+	refs, peeled, err := listRemote(ctx, remote, auth)
+	if err != nil {
+		return nil, remoteError(url, err)
+	}
+	for _, ref := range refs {
+		switch {
+		case ref.Name().IsTag():
+			if hash, ok := peeled[ref.Name()]; ok {
+				ref = plumbing.NewHashReference(ref.Name(), hash)
+			}
+		case ref.Name().IsBranch(), ref.Name() == plumbing.HEAD:
+		default:
+			continue
+		}
+		if err := repo.Storer.SetReference(ref); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// RemoteTag returns the reference of the tag on the given remote name or URL
+// and the commit it points to. If the remote has no such tag,
+// plumbing.ErrReferenceNotFound is returned.
+func RemoteTag(ctx context.Context, repo *git.Repository, remote, name string, auth transport.AuthMethod) (*plumbing.Reference, plumbing.Hash, error) {
+	r, err := Remote(repo, remote)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	refs, peeled, err := listRemote(ctx, r, auth)
+	if err != nil {
+		return nil, plumbing.ZeroHash, remoteError(remote, err)
+	}
+	refName := plumbing.NewTagReferenceName(name)
+	for _, ref := range refs {
+		if ref.Name() != refName {
+			continue
+		}
+		if commit, ok := peeled[refName]; ok {
+			return ref, commit, nil
+		}
+		return ref, ref.Hash(), nil
+	}
+	return nil, plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+}
+
+// listRemote returns the references of the remote, like git ls-remote. The
+// commits of annotated tags are returned by the name of the tag. An empty
+// remote has no references.
+func listRemote(ctx context.Context, remote *git.Remote, auth transport.AuthMethod) ([]*plumbing.Reference, map[plumbing.ReferenceName]plumbing.Hash, error) {
+	listed, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth, PeelingOption: git.AppendPeeled})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	refs := []*plumbing.Reference{}
+	peeled := map[plumbing.ReferenceName]plumbing.Hash{}
+	for _, ref := range listed {
+		if name, ok := strings.CutSuffix(ref.Name().String(), "^{}"); ok {
+			peeled[plumbing.ReferenceName(name)] = ref.Hash()
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, peeled, nil
+}
+
+// CloneRemote clones the remote URL with all tags into memory. The clone has
+// no worktree.
+func CloneRemote(ctx context.Context, url string, auth transport.AuthMethod, progress io.Writer) (*git.Repository, error) {
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:      url,
+		Auth:     auth,
+		Tags:     git.AllTags,
+		Progress: progress,
+	})
+	if err != nil {
+		return nil, remoteError(url, err)
+	}
+	return repo, nil
+}
+
+// remoteError maps the authentication errors of a remote to ErrAuthMissing.
+func remoteError(url string, err error) error {
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return fmt.Errorf("%w: %s", ErrAuthMissing, err)
+	}
+	return fmt.Errorf("could not read remote %q: %w", url, err)
+}
+
+// push pushes to the remote and maps the errors of the remote.
+func push(ctx context.Context, repo *git.Repository, remote, tag string, options *git.PushOptions) error {
+	r, err := Remote(repo, remote)
+	if err != nil {
+		return err
+	}
+	options.RemoteName = r.Config().Name
+	err = r.PushContext(ctx, options)
+	switch {
+	case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+		return nil
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		return fmt.Errorf("%w: %s", ErrAuthMissing, err)
+	}
+	return &PushError{Remote: remote, Tag: tag, Err: err}
+}
@@ -0,0 +1,466 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	gconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+var testSignature = &object.Signature{
+	Name:  "test",
+	Email: "test@example.com",
+	When:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// testRepo creates an in-memory repository with the given number of commits.
+// It returns the repository and the commit hashes from the oldest to the newest.
+func testRepo(t *testing.T, commits int) (*git.Repository, []plumbing.Hash) {
+	t.Helper()
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := []plumbing.Hash{}
+	for i := 0; i < commits; i++ {
+		hash, err := wt.Commit("commit", &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            testSignature,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return repo, hashes
+}
+
+// testTag creates a tag for the given commit. If annotated is true, an
+// annotated tag is created, otherwise a lightweight tag.
+func testTag(t *testing.T, repo *git.Repository, name string, hash plumbing.Hash, annotated bool) {
+	t.Helper()
+	var opts *git.CreateTagOptions
+	if annotated {
+		opts = &git.CreateTagOptions{Tagger: testSignature, Message: name}
+	}
+	if _, err := repo.CreateTag(name, hash, opts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveTag(t *testing.T) {
+	repo, hashes := testRepo(t, 2)
+	testTag(t, repo, "v1.0.0", hashes[0], false)
+	testTag(t, repo, "v1.1.0", hashes[1], true)
+
+	tests := []struct {
+		name    string
+		tag     string
+		want    plumbing.Hash
+		wantErr bool
+	}{
+		{
+			name: "lightweight",
+			tag:  "v1.0.0",
+			want: hashes[0],
+		},
+		{
+			name: "annotated",
+			tag:  "v1.1.0",
+			want: hashes[1],
+		},
+		{
+			name:    "not found",
+			tag:     "v2.0.0",
+			want:    plumbing.ZeroHash,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveTag(repo, tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveTag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolveTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	repo, hashes := testRepo(t, 3)
+	got, err := Ancestors(repo, hashes[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("Ancestors() returned %d commits, want 2", len(got))
+	}
+	for _, h := range hashes[:2] {
+		if _, ok := got[h]; !ok {
+			t.Errorf("Ancestors() is missing %v", h)
+		}
+	}
+	if _, ok := got[hashes[2]]; ok {
+		t.Errorf("Ancestors() contains descendant %v", hashes[2])
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	testTag(t, repo, "v1.0.0", hashes[0], false)
+
+	tests := []struct {
+		name    string
+		tag     string
+		wantErr error
+	}{
+		{
+			name: "new tag",
+			tag:  "v1.1.0",
+		},
+		{
+			name:    "existing tag",
+			tag:     "v1.0.0",
+			wantErr: ErrTagExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateTag(repo, tt.tag, hashes[0], nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPushTag(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	repo, hashes := testRepo(t, 2)
+	if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: "origin", URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tag     string
+		hash    plumbing.Hash
+		wantErr error
+	}{
+		{
+			name: "new tag",
+			tag:  "v1.0.0",
+			hash: hashes[1],
+		},
+		{
+			name: "up to date",
+			tag:  "v1.0.0",
+			hash: hashes[1],
+		},
+		{
+			// moving a tag to an older commit is not a fast-forward
+			name:    "tag moved",
+			tag:     "v1.0.0",
+			hash:    hashes[0],
+			wantErr: ErrPushRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := plumbing.NewHashReference(plumbing.NewTagReferenceName(tt.tag), tt.hash)
+			if err := repo.Storer.SetReference(ref); err != nil {
+				t.Fatal(err)
+			}
+			err := PushTag(context.Background(), repo, "origin", ref, nil, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("PushTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemote(t *testing.T) {
+	repo, _ := testRepo(t, 1)
+	if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: "origin", URLs: []string{"https://example.com/origin.git"}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		nameOrURL string
+		wantURL   string
+		wantErr   error
+	}{
+		{
+			name:      "configured remote",
+			nameOrURL: "origin",
+			wantURL:   "https://example.com/origin.git",
+		},
+		{
+			name:      "url",
+			nameOrURL: "git@example.com:mirror.git",
+			wantURL:   "git@example.com:mirror.git",
+		},
+		{
+			name:      "path",
+			nameOrURL: "/srv/git/mirror.git",
+			wantURL:   "/srv/git/mirror.git",
+		},
+		{
+			name:      "unknown remote",
+			nameOrURL: "mirror",
+			wantErr:   git.ErrRemoteNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Remote(repo, tt.nameOrURL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Remote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Config().URLs[0] != tt.wantURL {
+				t.Errorf("Remote() url = %v, want %v", got.Config().URLs[0], tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestDeleteRemoteTag(t *testing.T) {
+	dir := t.TempDir()
+	remote, err := git.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, hashes := testRepo(t, 1)
+	ref, err := repo.CreateTag("v1.0.0", hashes[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the remote is passed by its path instead of a configured name
+	if err := PushTag(context.Background(), repo, dir, ref, nil, nil); err != nil {
+		t.Fatalf("PushTag() error = %v", err)
+	}
+	if _, err := remote.Tag("v1.0.0"); err != nil {
+		t.Fatalf("remote tag error = %v", err)
+	}
+	if err := DeleteRemoteTag(context.Background(), repo, dir, ref, nil, nil); err != nil {
+		t.Fatalf("DeleteRemoteTag() error = %v", err)
+	}
+	if _, err := remote.Tag("v1.0.0"); !errors.Is(err, git.ErrTagNotFound) {
+		t.Errorf("remote tag error = %v, want %v", err, git.ErrTagNotFound)
+	}
+}
+
+// testShallowClone pushes a repository with five commits and the tags v1.0.0
+// and v1.1.0 to a bare remote and returns a clone of depth one without tags.
+func testShallowClone(t *testing.T) (*git.Repository, []plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	upstream, hashes := testRepo(t, 5)
+	for name, i := range map[string]int{"v1.0.0": 1, "v1.1.0": 3} {
+		if _, err := upstream.CreateTag(name, hashes[i], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := upstream.CreateRemote(&gconfig.RemoteConfig{Name: "origin", URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+	err := upstream.Push(&git.PushOptions{RefSpecs: []gconfig.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{URL: dir, Depth: 1, Tags: git.NoTags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, hashes
+}
+
+func TestFetchTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		deepen      bool
+		wantShallow bool
+		wantCommits int
+	}{
+		{
+			name:        "tags only",
+			wantShallow: true,
+			wantCommits: 1,
+		},
+		{
+			name:        "deepen",
+			deepen:      true,
+			wantCommits: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, hashes := testShallowClone(t)
+			if shallow, err := IsShallow(repo); err != nil || !shallow {
+				t.Fatalf("IsShallow() = %v, %v, want a shallow clone", shallow, err)
+			}
+
+			if err := FetchTags(context.Background(), repo, "origin", tt.deepen, nil, nil); err != nil {
+				t.Fatalf("FetchTags() error = %v", err)
+			}
+			hash, err := ResolveTag(repo, "v1.1.0")
+			if err != nil || hash != hashes[3] {
+				t.Errorf("ResolveTag() = %v, %v, want %v", hash, err, hashes[3])
+			}
+			shallow, err := IsShallow(repo)
+			if err != nil || shallow != tt.wantShallow {
+				t.Errorf("IsShallow() = %v, %v, want %v", shallow, err, tt.wantShallow)
+			}
+
+			// the log stops at the shallow commits
+			iter, err := repo.Log(&git.LogOptions{From: hashes[4]})
+			if err != nil {
+				t.Fatal(err)
+			}
+			commits := 0
+			_ = iter.ForEach(func(*object.Commit) error {
+				commits++
+				return nil
+			})
+			if commits != tt.wantCommits {
+				t.Errorf("history has %d commits, want %d", commits, tt.wantCommits)
+			}
+		})
+	}
+}
+
+// testRemote pushes a repository with three commits, the lightweight tag
+// v1.0.0 and the annotated tag v1.1.0 to a bare remote and returns its URL.
+func testRemote(t *testing.T) (string, []plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	upstream, hashes := testRepo(t, 3)
+	if _, err := upstream.CreateTag("v1.0.0", hashes[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := upstream.CreateTag("v1.1.0", hashes[1], &git.CreateTagOptions{Tagger: testSignature, Message: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := upstream.CreateRemote(&gconfig.RemoteConfig{Name: "origin", URLs: []string{dir}}); err != nil {
+		t.Fatal(err)
+	}
+	err := upstream.Push(&git.PushOptions{RefSpecs: []gconfig.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "file://" + dir, hashes
+}
+
+func TestListRemote(t *testing.T) {
+	url, hashes := testRemote(t)
+	repo, err := ListRemote(context.Background(), url, nil)
+	if err != nil {
+		t.Fatalf("ListRemote() error = %v", err)
+	}
+	for tag, want := range map[string]plumbing.Hash{"v1.0.0": hashes[0], "v1.1.0": hashes[1]} {
+		got, err := ResolveTag(repo, tag)
+		if err != nil || got != want {
+			t.Errorf("ResolveTag(%q) = %v, %v, want %v", tag, got, err, want)
+		}
+	}
+	head, err := repo.Head()
+	if err != nil || head.Hash() != hashes[2] {
+		t.Errorf("Head() = %v, %v, want %v", head, err, hashes[2])
+	}
+
+	if _, err := ListRemote(context.Background(), "file:///nonexistent/repo.git", nil); err == nil {
+		t.Errorf("ListRemote() of a missing remote error = nil, want an error")
+	}
+}
+
+func TestCloneRemote(t *testing.T) {
+	url, hashes := testRemote(t)
+	repo, err := CloneRemote(context.Background(), url, nil, nil)
+	if err != nil {
+		t.Fatalf("CloneRemote() error = %v", err)
+	}
+	got, err := ResolveTag(repo, "v1.1.0")
+	if err != nil || got != hashes[1] {
+		t.Errorf("ResolveTag() = %v, %v, want %v", got, err, hashes[1])
+	}
+	ancestors, err := Ancestors(repo, hashes[2])
+	if err != nil || len(ancestors) != 3 {
+		t.Errorf("Ancestors() = %v, %v, want the 3 commits", ancestors, err)
+	}
+}
+
+func TestRemoteTag(t *testing.T) {
+	url, hashes := testRemote(t)
+	repo, _ := testRepo(t, 1)
+	if _, err := repo.CreateRemote(&gconfig.RemoteConfig{Name: "origin", URLs: []string{url}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		tag      string
+		want     plumbing.Hash
+		wantPeel bool
+		wantErr  error
+	}{
+		{
+			name: "lightweight tag",
+			tag:  "v1.0.0",
+			want: hashes[0],
+		},
+		{
+			name:     "annotated tag",
+			tag:      "v1.1.0",
+			want:     hashes[1],
+			wantPeel: true,
+		},
+		{
+			name:    "missing tag",
+			tag:     "v2.0.0",
+			wantErr: plumbing.ErrReferenceNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, got, err := RemoteTag(context.Background(), repo, "origin", tt.tag, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoteTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RemoteTag() commit = %v, want %v", got, tt.want)
+			}
+			// the reference of an annotated tag points to the tag object
+			if peeled := ref.Hash() != got; peeled != tt.wantPeel {
+				t.Errorf("RemoteTag() ref = %v, want the tag object %v", ref.Hash(), tt.wantPeel)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	ErrOutsideVersionLine = fmt.Errorf("version is outside of the version line")
+)
+
+// VersionLine is a range of versions sharing the same major and optionally
+// the same minor version, e.g. 1.x or 1.4.x. Version lines are used by
+// maintenance branches that must not leave their line.
+type VersionLine struct {
+	Major uint64
+	Minor uint64
+	// MinorFixed reports whether the minor version is part of the line.
+	MinorFixed bool
+}
+
+// String returns the version line in the format 1.x or 1.4.x.
+func (l *VersionLine) String() string {
+	if l.MinorFixed {
+		return fmt.Sprintf("%d.%d.x", l.Major, l.Minor)
+	}
+	return fmt.Sprintf("%d.x", l.Major)
+}
+
+// Contains returns true if the given version belongs to the version line.
+func (l *VersionLine) Contains(v *semver.Version) bool {
+	if v.Major() != l.Major {
+		return false
+	}
+	return !l.MinorFixed || v.Minor() == l.Minor
+}
+
+// Check returns an error if the given version does not belong to the version line.
+func (l *VersionLine) Check(v *semver.Version) error {
+	if !l.Contains(v) {
+		return fmt.Errorf("%w: %s is not part of %s", ErrOutsideVersionLine, v, l)
+	}
+	return nil
+}
+
+// base returns the first version of the version line.
+func (l *VersionLine) base() *semver.Version {
+	return semver.MustParse(fmt.Sprintf("v%d.%d.0", l.Major, l.Minor))
+}
@@ -0,0 +1,74 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestVersionLine_String(t *testing.T) {
+	tests := []struct {
+		name string
+		l    *VersionLine
+		want string
+	}{
+		{
+			name: "major",
+			l:    &VersionLine{Major: 1},
+			want: "1.x",
+		},
+		{
+			name: "major and minor",
+			l:    &VersionLine{Major: 1, Minor: 4, MinorFixed: true},
+			want: "1.4.x",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.l.String(); got != tt.want {
+				t.Errorf("VersionLine.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionLine_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		l       *VersionLine
+		version *semver.Version
+		wantErr bool
+	}{
+		{
+			name:    "major line",
+			l:       &VersionLine{Major: 1},
+			version: semver.MustParse("v1.7.3"),
+			wantErr: false,
+		},
+		{
+			name:    "major line with other major",
+			l:       &VersionLine{Major: 1},
+			version: semver.MustParse("v2.0.0"),
+			wantErr: true,
+		},
+		{
+			name:    "minor line",
+			l:       &VersionLine{Major: 1, Minor: 4, MinorFixed: true},
+			version: semver.MustParse("v1.4.9-rc.1"),
+			wantErr: false,
+		},
+		{
+			name:    "minor line with other minor",
+			l:       &VersionLine{Major: 1, Minor: 4, MinorFixed: true},
+			version: semver.MustParse("v1.5.0"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.l.Check(tt.version); (err != nil) != tt.wantErr {
+				t.Errorf("VersionLine.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrInvalidMetadata = fmt.Errorf("build metadata is invalid")
+
+	metadataRegEx = regexp.MustCompile(`^[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*$`)
+)
+
+type MetadataFormat string
+
+const (
+	// MetadataFormatSHA is the format for build metadata that uses the short
+	// hash of the tagged commit, e.g. sha.abc1234.
+	MetadataFormatSHA MetadataFormat = "sha"
+	// MetadataFormatBuild is the format for build metadata that uses a build
+	// number, e.g. build.512.
+	MetadataFormatBuild MetadataFormat = "build"
+	// MetadataFormatTimestamp is the format for build metadata that uses the
+	// current date and time in UTC, e.g. 20261017063151.
+	MetadataFormatTimestamp MetadataFormat = "timestamp"
+)
+
+func (m MetadataFormat) String() string {
+	return string(m)
+}
+
+// BuildMetadata returns the build metadata in the given format.
+// The sha is the hash of the tagged commit and build the build number, e.g.
+// taken from a CI environment variable. An empty format returns no metadata.
+func BuildMetadata(format MetadataFormat, sha, build string) (string, error) {
+	var metadata string
+	switch format {
+	case "":
+		return "", nil
+	case MetadataFormatSHA:
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		metadata = "sha." + sha
+	case MetadataFormatBuild:
+		if build == "" {
+			return "", fmt.Errorf("%w: no build number", ErrInvalidMetadata)
+		}
+		metadata = "build." + build
+	case MetadataFormatTimestamp:
+		metadata = time.Now().UTC().Format("20060102150405")
+	default:
+		return "", fmt.Errorf("%w: unknown format %q", ErrInvalidMetadata, format)
+	}
+	if !metadataRegEx.MatchString(metadata) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMetadata, metadata)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,74 @@
+package release
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetadataFormat_String(t *testing.T) {
+	if got := MetadataFormatSHA.String(); got != "sha" {
+		t.Errorf("MetadataFormat.String() = %v, want %v", got, "sha")
+	}
+}
+
+func TestBuildMetadata(t *testing.T) {
+	type args struct {
+		format MetadataFormat
+		sha    string
+		build  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "none",
+			args: args{format: ""},
+			want: "",
+		},
+		{
+			name: "sha",
+			args: args{format: MetadataFormatSHA, sha: "abc1234def5678"},
+			want: "sha.abc1234",
+		},
+		{
+			name: "build",
+			args: args{format: MetadataFormatBuild, build: "512"},
+			want: "build.512",
+		},
+		{
+			name:    "build without number",
+			args:    args{format: MetadataFormatBuild},
+			wantErr: true,
+		},
+		{
+			name:    "build with invalid characters",
+			args:    args{format: MetadataFormatBuild, build: "12+3"},
+			wantErr: true,
+		},
+		{
+			name: "timestamp",
+			args: args{format: MetadataFormatTimestamp},
+			want: time.Now().UTC().Format("20060102150405"),
+		},
+		{
+			name:    "unknown",
+			args:    args{format: "unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildMetadata(tt.args.format, tt.args.sha, tt.args.build)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildMetadata() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BuildMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,10 @@
 package release
 
 import (
+	"errors"
 	"fmt"
-	"log"
-	"regexp"
+	"io"
+	"log/slog"
 	"sort"
 	"strconv"
 	"strings"
@@ -14,6 +15,13 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 )
 
+var (
+	ErrNotPreRelease   = fmt.Errorf("version is not a pre-release")
+	ErrNoTags          = fmt.Errorf("no matching tag found")
+	ErrNoReleaseNeeded = fmt.Errorf("no release needed")
+	ErrPreRelease      = fmt.Errorf("pre-release is invalid")
+)
+
 type PreReleaseFormat string
 
 const (
@@ -48,6 +56,32 @@ func (s SemVerBumpType) String() string {
 	return string(s)
 }
 
+// weight returns the precedence of the bump type. Unknown bump types are
+// treated like SemVerBumpTypeNone.
+func (s SemVerBumpType) weight() int {
+	switch s {
+	case SemVerBumpTypeMajor:
+		return 3
+	case SemVerBumpTypeMinor:
+		return 2
+	case SemVerBumpTypePatch:
+		return 1
+	}
+	return 0
+}
+
+// Compare compares the bump type with another one. It returns -1 if s is a
+// smaller bump than o, 0 if both are equal and 1 if s is a larger bump than o.
+func (s SemVerBumpType) Compare(o SemVerBumpType) int {
+	switch {
+	case s.weight() < o.weight():
+		return -1
+	case s.weight() > o.weight():
+		return 1
+	}
+	return 0
+}
+
 // bumpPreRelease bumps the prerelease version of a given semver.Version.
 // The bumping is done according to the given PreReleaseFormat.
 func bumpPreRelease(smvFormat PreReleaseFormat, version semver.Version, preReleasePrefix string) string {
@@ -62,6 +96,10 @@ func bumpPreRelease(smvFormat PreReleaseFormat, version semver.Version, preRelea
 		if version.Prerelease() == "" {
 			return fmt.Sprintf("%s-%s.%s", tagPrefix, preReleasePrefix, "1")
 		}
+		// every channel has its own counter
+		if preReleaseChannel(version.Prerelease()) != preReleasePrefix {
+			return fmt.Sprintf("%s-%s.%d", tagPrefix, preReleasePrefix, 1)
+		}
 		intVers, err := strconv.Atoi(strings.TrimPrefix(version.Prerelease(), preReleasePrefix+"."))
 		if err != nil {
 			return fmt.Sprintf("%s.%d", version.String(), 1)
@@ -75,9 +113,106 @@ func bumpPreRelease(smvFormat PreReleaseFormat, version semver.Version, preRelea
 	return fmt.Sprintf("%s-%s.%d", tagPrefix, preReleasePrefix, 1)
 }
 
+// TagOption configures which tags are considered by GetLatestSemVerTagFromRepo.
+type TagOption func(*tagOptions)
+
+type tagOptions struct {
+	prefix        string
+	reachableFrom plumbing.Hash
+	pointsAt      plumbing.Hash
+	line          *VersionLine
+	scheme        Scheme
+	trace         *Trace
+	log           *slog.Logger
+	required      bool
+}
+
+// WithTagPrefix only considers tags starting with the given prefix, e.g.
+// "services/api/". The prefix is removed before the tag is parsed, so the
+// returned version does not contain it.
+func WithTagPrefix(prefix string) TagOption {
+	return func(o *tagOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithReachableFrom only considers tags that point to the given commit or one
+// of its ancestors, similar to git describe. Annotated tags are peeled to the
+// commit they reference.
+func WithReachableFrom(hash plumbing.Hash) TagOption {
+	return func(o *tagOptions) {
+		o.reachableFrom = hash
+	}
+}
+
+// WithPointsAt only considers tags that point to the given commit, similar
+// to git tag --points-at. Annotated tags are peeled to the commit they
+// reference.
+func WithPointsAt(hash plumbing.Hash) TagOption {
+	return func(o *tagOptions) {
+		o.pointsAt = hash
+	}
+}
+
+// WithVersionLine only considers tags that belong to the given version line.
+// If no tag of the line exists, the first version of the line is returned
+// instead of v0.0.0. A nil version line considers all tags.
+func WithVersionLine(line *VersionLine) TagOption {
+	return func(o *tagOptions) {
+		o.line = line
+	}
+}
+
+// WithScheme only considers tags that are versions of the given scheme.
+// By default, the SemVer scheme is used.
+func WithScheme(scheme Scheme) TagOption {
+	return func(o *tagOptions) {
+		o.scheme = scheme
+	}
+}
+
+// WithTrace records which tags were found, which were skipped and why, and
+// which tag was chosen as latest tag.
+func WithTrace(trace *Trace) TagOption {
+	return func(o *tagOptions) {
+		o.trace = trace
+	}
+}
+
+// WithLogger logs which tag was chosen as latest tag. By default, nothing is
+// logged.
+func WithLogger(log *slog.Logger) TagOption {
+	return func(o *tagOptions) {
+		o.log = log
+	}
+}
+
+// WithRequiredTag returns ErrNoTags if no tag matches, instead of v0.0.0 or
+// the first version of the version line.
+func WithRequiredTag() TagOption {
+	return func(o *tagOptions) {
+		o.required = true
+	}
+}
+
 // GetLatestSemVerTagFromRepo returns the latest semver tag from a given git repository.
 // If no semver tag is found, it returns a semver.Version with the value v0.0.0.
-func GetLatestSemVerTagFromRepo(repo *git.Repository, isPreRelease bool) (*semver.Version, error) {
+func GetLatestSemVerTagFromRepo(repo *git.Repository, isPreRelease bool, opts ...TagOption) (*semver.Version, error) {
+	options := &tagOptions{scheme: SemVer{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+	log := logger(options.log)
+
+	var reachable map[plumbing.Hash]struct{}
+	if !options.reachableFrom.IsZero() {
+		ancestors, err := Ancestors(repo, options.reachableFrom)
+		if err != nil {
+			return nil, err
+		}
+		reachable = ancestors
+	}
+
 	// get tags from repository
 	tags, err := repo.Tags()
 	if err != nil {
@@ -85,21 +220,56 @@ func GetLatestSemVerTagFromRepo(repo *git.Repository, isPreRelease bool) (*semve
 	}
 	// get tags from repository
 	vs := []*semver.Version{}
-	tags.ForEach(func(t *plumbing.Reference) error {
+	err = tags.ForEach(func(t *plumbing.Reference) error {
 		gitTag := strings.TrimPrefix(t.Name().String(), "refs/tags/")
-		// check if tag matches semver format
-		isSemver := regexp.MustCompile("^[v]{0,1}[0-9]{1,}.[0-9]{1,}.[0-9]{1,}(-[a-zA-Z0-9.-]+){0,1}$").MatchString(gitTag)
-		if !isSemver {
+		// only consider tags of the configured prefix
+		if !strings.HasPrefix(gitTag, options.prefix) {
+			options.trace.Addf("skipped tag %q: does not start with the prefix %q", gitTag, options.prefix)
+			return nil
+		}
+		// check if tag matches the format of the scheme
+		smv, err := options.scheme.Parse(strings.TrimPrefix(gitTag, options.prefix))
+		if errors.Is(err, ErrSchemeMismatch) {
+			options.trace.Addf("skipped tag %q: not a %s version", gitTag, options.scheme)
 			return nil
 		}
-		//
-		smv, err := semver.NewVersion(gitTag)
 		if err != nil {
 			return err
 		}
+		// check if tag is reachable from the configured commit
+		if reachable != nil {
+			hash, err := peelTag(repo, t)
+			if err != nil {
+				return err
+			}
+			if _, ok := reachable[hash]; !ok {
+				options.trace.Addf("skipped tag %q: not reachable from %s", gitTag, options.reachableFrom)
+				return nil
+			}
+		}
+		// check if tag points to the configured commit
+		if !options.pointsAt.IsZero() {
+			hash, err := peelTag(repo, t)
+			if err != nil {
+				return err
+			}
+			if hash != options.pointsAt {
+				options.trace.Addf("skipped tag %q: does not point to %s", gitTag, options.pointsAt)
+				return nil
+			}
+		}
+		// check if tag belongs to the configured version line
+		if options.line != nil && !options.line.Contains(smv) {
+			options.trace.Addf("skipped tag %q: not part of the version line %s", gitTag, options.line)
+			return nil
+		}
+		options.trace.Addf("found tag %q", gitTag)
 		vs = append(vs, smv)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 	// sort tags
 	sort.Sort(semver.Collection(vs))
 
@@ -107,12 +277,14 @@ func GetLatestSemVerTagFromRepo(repo *git.Repository, isPreRelease bool) (*semve
 	var latest *semver.Version
 	if len(vs) > 0 {
 		if vs[len(vs)-1].Prerelease() != "" && isPreRelease {
-			log.Println("Latest tag is a pre-release version", vs[len(vs)-1])
+			log.Info("latest tag is a pre-release version", "version", vs[len(vs)-1])
+			options.trace.Addf("chose %q as latest tag: it is the highest version and a pre-release, which is considered for pre-releases", options.prefix+vs[len(vs)-1].Original())
 			latest = vs[len(vs)-1]
 		} else {
 			for i := len(vs) - 1; i >= 0; i-- {
 				if vs[i].Prerelease() == "" {
-					log.Println("Latest tag is a non pre-release version", vs[i])
+					log.Info("latest tag is a non pre-release version", "version", vs[i])
+					options.trace.Addf("chose %q as latest tag: it is the highest version that is not a pre-release", options.prefix+vs[i].Original())
 					latest = vs[i]
 					break
 				}
@@ -120,30 +292,138 @@ func GetLatestSemVerTagFromRepo(repo *git.Repository, isPreRelease bool) (*semve
 		}
 	}
 
+	if latest == nil && options.required {
+		options.trace.Addf("no matching tag found")
+		return nil, ErrNoTags
+	}
+	if latest == nil && options.line != nil {
+		options.trace.Addf("no matching tag found: using %s, the first version of the version line %s", options.line.base().Original(), options.line)
+		return options.line.base(), nil
+	}
 	if latest == nil {
+		options.trace.Addf("no matching tag found: using v0.0.0")
 		return semver.MustParse("v0.0.0"), nil
 	}
 
 	return latest, nil
 }
 
+// BumpOptions describes how a version is bumped.
+type BumpOptions struct {
+	Type             SemVerBumpType
+	PreRelease       bool
+	PreReleaseFormat PreReleaseFormat
+	PreReleasePrefix string
+	// Channels restricts the pre-release prefix to the configured channels.
+	// If empty, any prefix is allowed.
+	Channels Channels
+	// Metadata is the build metadata of the new version, e.g. sha.abc1234.
+	Metadata string
+	// Scheme bumps the version core. By default, the SemVer scheme is used.
+	Scheme Scheme
+	// Logger receives the diagnostics of the bump. By default, nothing is
+	// logged.
+	Logger *slog.Logger
+}
+
+// Bump takes a semver.Version and returns the version bumped according to the
+// given options. An error is returned if the pre-release channel is not
+// allowed, if the pre-release would move to an earlier channel or if the
+// pre-release prefix is not a valid pre-release identifier.
+func Bump(latest *semver.Version, opts BumpOptions) (*semver.Version, error) {
+	if opts.Scheme == nil {
+		opts.Scheme = SemVer{}
+	}
+	newTag := opts.Scheme.Bump(latest, opts.Type)
+	if opts.PreRelease {
+		// the channel of an existing pre-release is only relevant if the version core is kept
+		from := ""
+		if newTag.Prerelease() != "" {
+			from = preReleaseChannel(newTag.Prerelease())
+		}
+		if err := opts.Channels.Check(from, opts.PreReleasePrefix); err != nil {
+			return nil, err
+		}
+		logger(opts.Logger).Debug("bumping pre-release version", "version", newTag.String())
+		vrs := bumpPreRelease(opts.PreReleaseFormat, newTag, opts.PreReleasePrefix)
+		// the prefix is user input and may not be a valid pre-release identifier
+		preRelease, err := semver.NewVersion(vrs)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q with the prefix %q: %w", ErrPreRelease, vrs, opts.PreReleasePrefix, err)
+		}
+		newTag = *preRelease
+	}
+	newTag, err := newTag.SetMetadata(opts.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, err)
+	}
+	return &newTag, nil
+}
+
 // BumpTag takes a semver.Version and a semVerBumpType and returns the
-// bumped version as a string.
-func BumpTag(latest *semver.Version, semVerType SemVerBumpType, preReleaseFormat PreReleaseFormat, preReleasePrefix string, isPreRelease bool) string {
-	formattedLatest := semver.New(latest.Major(), latest.Minor(), latest.Patch(), "", "")
-	newTag := *latest
-	switch semVerType {
-	case SemVerBumpTypeMajor:
-		newTag = formattedLatest.IncMajor()
-	case SemVerBumpTypeMinor:
-		newTag = formattedLatest.IncMinor()
-	case SemVerBumpTypePatch:
-		newTag = formattedLatest.IncPatch()
+// bumped version as a string. An error is returned if the pre-release prefix
+// is not a valid pre-release identifier.
+func BumpTag(latest *semver.Version, semVerType SemVerBumpType, preReleaseFormat PreReleaseFormat, preReleasePrefix string, isPreRelease bool) (string, error) {
+	newTag, err := Bump(latest, BumpOptions{
+		Type:             semVerType,
+		PreRelease:       isPreRelease,
+		PreReleaseFormat: preReleaseFormat,
+		PreReleasePrefix: preReleasePrefix,
+	})
+	if err != nil {
+		return "", err
+	}
+	return newTag.String(), nil
+}
+
+// BumpPendingPreRelease bumps a pre-release version while another pre-release
+// is pending, i.e. the latest pre-release is newer than the latest release.
+// The version core is only moved if the requested bump is larger than the bump
+// the pending pre-release already covers compared to the latest release.
+// Otherwise only the pre-release counter is incremented.
+func BumpPendingPreRelease(latestRelease, latestPreRelease *semver.Version, opts BumpOptions) (*semver.Version, error) {
+	opts.PreRelease = true
+	if latestPreRelease.Prerelease() == "" || !latestPreRelease.GreaterThan(latestRelease) {
+		return Bump(latestRelease, opts)
+	}
+	if opts.Type.Compare(coveredBumpType(latestRelease, latestPreRelease)) > 0 {
+		logger(opts.Logger).Info("requested bump exceeds the pending pre-release", "version", latestPreRelease)
+		return Bump(latestRelease, opts)
+	}
+	opts.Type = SemVerBumpTypeNone
+	return Bump(latestPreRelease, opts)
+}
+
+// discardLogger drops all records, so the package is silent by default.
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// logger returns the given logger, or a logger that drops all records if it
+// is nil.
+func logger(log *slog.Logger) *slog.Logger {
+	if log == nil {
+		return discardLogger
 	}
-	if isPreRelease {
-		log.Println("Bumping pre-release version", newTag)
-		vrs := bumpPreRelease(preReleaseFormat, newTag, preReleasePrefix)
-		newTag = *semver.MustParse(vrs)
+	return log
+}
+
+// coveredBumpType returns the bump type that leads from the latest release
+// to the version core of the pre-release.
+func coveredBumpType(latestRelease, latestPreRelease *semver.Version) SemVerBumpType {
+	switch {
+	case latestPreRelease.Major() > latestRelease.Major():
+		return SemVerBumpTypeMajor
+	case latestPreRelease.Minor() > latestRelease.Minor():
+		return SemVerBumpTypeMinor
+	}
+	return SemVerBumpTypePatch
+}
+
+// Promote returns the stable release of the given pre-release, e.g. v2.0.0
+// for v2.0.0-rc.4. If the given version is not a pre-release, ErrNotPreRelease
+// is returned.
+func Promote(preRelease *semver.Version) (*semver.Version, error) {
+	if preRelease.Prerelease() == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotPreRelease, preRelease.Original())
 	}
-	return newTag.String()
+	return semver.New(preRelease.Major(), preRelease.Minor(), preRelease.Patch(), "", ""), nil
 }
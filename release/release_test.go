@@ -1,12 +1,19 @@
 package release
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/storage/memory"
 )
 
@@ -165,6 +172,15 @@ func Test_bumpPreRelease(t *testing.T) {
 			want: "v1.0.0-alpha.1",
 		},
 
+		{
+			name: "PreReleaseFormatSemVer with other channel",
+			args: args{
+				smvFormat:        PreReleaseFormatSemVer,
+				version:          *semver.MustParse("1.2.0-beta.3"),
+				preReleasePrefix: "rc",
+			},
+			want: "1.2.0-rc.1",
+		},
 		{
 			name: "PreReleaseFormatSemVer with alpha prerelease and not a number",
 			args: args{
@@ -237,10 +253,22 @@ func Test_BumpTag(t *testing.T) {
 		isPreRelease     bool
 	}
 	tests := []struct {
-		name string
-		args args
-		want string
+		name    string
+		args    args
+		want    string
+		wantErr bool
 	}{
+		{
+			name: "invalid pre-release prefix",
+			args: args{
+				latest:           semver.MustParse("v1.2.0"),
+				semVerType:       SemVerBumpTypePatch,
+				preReleaseFormat: PreReleaseFormatSemVer,
+				preReleasePrefix: "rc_1",
+				isPreRelease:     true,
+			},
+			wantErr: true,
+		},
 		{
 			name: "patch",
 			args: args{
@@ -366,9 +394,635 @@ func Test_BumpTag(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := BumpTag(tt.args.latest, tt.args.semVerType, tt.args.preReleaseFormat, tt.args.preReleasePrefix, tt.args.isPreRelease); got != tt.want {
+			got, err := BumpTag(tt.args.latest, tt.args.semVerType, tt.args.preReleaseFormat, tt.args.preReleasePrefix, tt.args.isPreRelease)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BumpTag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
 				t.Errorf("BumpTag() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestSemVerBumpType_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		s    SemVerBumpType
+		o    SemVerBumpType
+		want int
+	}{
+		{
+			name: "major greater than minor",
+			s:    SemVerBumpTypeMajor,
+			o:    SemVerBumpTypeMinor,
+			want: 1,
+		},
+		{
+			name: "patch less than minor",
+			s:    SemVerBumpTypePatch,
+			o:    SemVerBumpTypeMinor,
+			want: -1,
+		},
+		{
+			name: "none equals empty",
+			s:    SemVerBumpTypeNone,
+			o:    "",
+			want: 0,
+		},
+		{
+			name: "patch greater than none",
+			s:    SemVerBumpTypePatch,
+			o:    SemVerBumpTypeNone,
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Compare(tt.o); got != tt.want {
+				t.Errorf("SemVerBumpType.Compare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithTagPrefix(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	for _, tag := range []string{"v3.0.0", "services/api/v1.4.2", "services/api/v1.5.0-rc.1", "services/worker/v0.9.0"} {
+		testTag(t, repo, tag, hashes[0], false)
+	}
+
+	tests := []struct {
+		name         string
+		prefix       string
+		isPreRelease bool
+		want         string
+	}{
+		{
+			name:   "no prefix",
+			prefix: "",
+			want:   "v3.0.0",
+		},
+		{
+			name:   "api",
+			prefix: "services/api/",
+			want:   "v1.4.2",
+		},
+		{
+			name:         "api pre-release",
+			prefix:       "services/api/",
+			isPreRelease: true,
+			want:         "v1.5.0-rc.1",
+		},
+		{
+			name:   "worker",
+			prefix: "services/worker/",
+			want:   "v0.9.0",
+		},
+		{
+			name:   "unknown component",
+			prefix: "services/web/",
+			want:   "v0.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLatestSemVerTagFromRepo(repo, tt.isPreRelease, WithTagPrefix(tt.prefix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Original() != tt.want {
+				t.Errorf("GetLatestSemVerTagFromRepo() = %v, want %v", got.Original(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithReachableFrom(t *testing.T) {
+	// main: c0 - c1 - c2, release/1.x: c0 - r1
+	repo, hashes := testRepo(t, 3)
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = wt.Checkout(&git.CheckoutOptions{Hash: hashes[0], Branch: plumbing.NewBranchReferenceName("release/1.x"), Create: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	maintenance, err := wt.Commit("maintenance", &git.CommitOptions{AllowEmptyCommits: true, Author: testSignature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTag(t, repo, "v1.0.0", hashes[0], false)
+	testTag(t, repo, "v1.0.1", maintenance, true)
+	testTag(t, repo, "v2.0.0", hashes[1], true)
+	testTag(t, repo, "v2.3.0", hashes[2], false)
+
+	tests := []struct {
+		name string
+		from plumbing.Hash
+		want string
+	}{
+		{
+			name: "all tags",
+			from: plumbing.ZeroHash,
+			want: "v2.3.0",
+		},
+		{
+			name: "main",
+			from: hashes[2],
+			want: "v2.3.0",
+		},
+		{
+			name: "main before latest tag",
+			from: hashes[1],
+			want: "v2.0.0",
+		},
+		{
+			name: "maintenance branch",
+			from: maintenance,
+			want: "v1.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLatestSemVerTagFromRepo(repo, false, WithReachableFrom(tt.from))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Original() != tt.want {
+				t.Errorf("GetLatestSemVerTagFromRepo() = %v, want %v", got.Original(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithPointsAt(t *testing.T) {
+	repo, hashes := testRepo(t, 3)
+	testTag(t, repo, "v1.0.0", hashes[0], false)
+	testTag(t, repo, "v1.1.0-rc.1", hashes[1], false)
+	testTag(t, repo, "v1.1.0", hashes[1], true)
+	testTag(t, repo, "v1.2.0-rc.1", hashes[2], true)
+
+	tests := []struct {
+		name         string
+		at           plumbing.Hash
+		isPreRelease bool
+		want         string
+		wantErr      error
+	}{
+		{
+			name: "lightweight tag",
+			at:   hashes[0],
+			want: "v1.0.0",
+		},
+		{
+			name: "release and pre-release of the commit",
+			at:   hashes[1],
+			want: "v1.1.0",
+		},
+		{
+			name:         "pre-release",
+			at:           hashes[2],
+			isPreRelease: true,
+			want:         "v1.2.0-rc.1",
+		},
+		{
+			name:    "pre-release without pre-releases",
+			at:      hashes[2],
+			wantErr: ErrNoTags,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLatestSemVerTagFromRepo(repo, tt.isPreRelease, WithPointsAt(tt.at), WithRequiredTag())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetLatestSemVerTagFromRepo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Original() != tt.want {
+				t.Errorf("GetLatestSemVerTagFromRepo() = %v, want %v", got.Original(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithLogger(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	testTag(t, repo, "v1.0.0", hashes[0], false)
+	buf := &bytes.Buffer{}
+	_, err := GetLatestSemVerTagFromRepo(repo, false, WithLogger(slog.New(slog.NewTextHandler(buf, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "latest tag is a non pre-release version") {
+		t.Errorf("GetLatestSemVerTagFromRepo() logged %q, want the latest tag", buf.String())
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithVersionLine(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	for _, tag := range []string{"v1.3.2", "v1.4.0", "v1.4.1", "v2.0.0"} {
+		testTag(t, repo, tag, hashes[0], false)
+	}
+
+	tests := []struct {
+		name string
+		line *VersionLine
+		want string
+	}{
+		{
+			name: "no line",
+			line: nil,
+			want: "v2.0.0",
+		},
+		{
+			name: "major line",
+			line: &VersionLine{Major: 1},
+			want: "v1.4.1",
+		},
+		{
+			name: "minor line",
+			line: &VersionLine{Major: 1, Minor: 3, MinorFixed: true},
+			want: "v1.3.2",
+		},
+		{
+			name: "line without tags",
+			line: &VersionLine{Major: 3},
+			want: "v3.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLatestSemVerTagFromRepo(repo, false, WithVersionLine(tt.line))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Original() != tt.want {
+				t.Errorf("GetLatestSemVerTagFromRepo() = %v, want %v", got.Original(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_BumpPendingPreRelease(t *testing.T) {
+	type args struct {
+		latestRelease    *semver.Version
+		latestPreRelease *semver.Version
+		semVerType       SemVerBumpType
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "minor covered by pending minor",
+			args: args{
+				latestRelease:    semver.MustParse("v1.2.0"),
+				latestPreRelease: semver.MustParse("v1.3.0-rc.2"),
+				semVerType:       SemVerBumpTypeMinor,
+			},
+			want: "1.3.0-rc.3",
+		},
+		{
+			name: "patch covered by pending minor",
+			args: args{
+				latestRelease:    semver.MustParse("v1.2.0"),
+				latestPreRelease: semver.MustParse("v1.3.0-rc.2"),
+				semVerType:       SemVerBumpTypePatch,
+			},
+			want: "1.3.0-rc.3",
+		},
+		{
+			name: "major exceeds pending minor",
+			args: args{
+				latestRelease:    semver.MustParse("v1.2.0"),
+				latestPreRelease: semver.MustParse("v1.3.0-rc.2"),
+				semVerType:       SemVerBumpTypeMajor,
+			},
+			want: "2.0.0-rc.1",
+		},
+		{
+			name: "minor exceeds pending patch",
+			args: args{
+				latestRelease:    semver.MustParse("v1.2.0"),
+				latestPreRelease: semver.MustParse("v1.2.1-rc.1"),
+				semVerType:       SemVerBumpTypeMinor,
+			},
+			want: "1.3.0-rc.1",
+		},
+		{
+			name: "minor covered by pending major",
+			args: args{
+				latestRelease:    semver.MustParse("v1.2.0"),
+				latestPreRelease: semver.MustParse("v2.0.0-rc.1"),
+				semVerType:       SemVerBumpTypeMinor,
+			},
+			want: "2.0.0-rc.2",
+		},
+		{
+			name: "none increments counter",
+			args: args{
+				latestRelease:    semver.MustParse("v1.2.0"),
+				latestPreRelease: semver.MustParse("v1.3.0-rc.2"),
+				semVerType:       SemVerBumpTypeNone,
+			},
+			want: "1.3.0-rc.3",
+		},
+		{
+			name: "no pending pre-release",
+			args: args{
+				latestRelease:    semver.MustParse("v1.3.0"),
+				latestPreRelease: semver.MustParse("v1.3.0"),
+				semVerType:       SemVerBumpTypeMinor,
+			},
+			want: "1.4.0-rc.1",
+		},
+		{
+			name: "pre-release older than release",
+			args: args{
+				latestRelease:    semver.MustParse("v1.3.0"),
+				latestPreRelease: semver.MustParse("v1.3.0-rc.2"),
+				semVerType:       SemVerBumpTypePatch,
+			},
+			want: "1.3.1-rc.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BumpPendingPreRelease(tt.args.latestRelease, tt.args.latestPreRelease, BumpOptions{
+				Type:             tt.args.semVerType,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "rc",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("BumpPendingPreRelease() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Promote(t *testing.T) {
+	tests := []struct {
+		name       string
+		preRelease *semver.Version
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "release candidate",
+			preRelease: semver.MustParse("v2.0.0-rc.4"),
+			want:       "2.0.0",
+		},
+		{
+			name:       "pre-release with metadata",
+			preRelease: semver.MustParse("1.3.1-beta.2+build.7"),
+			want:       "1.3.1",
+		},
+		{
+			name:       "not a pre-release",
+			preRelease: semver.MustParse("v2.0.0"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Promote(tt.preRelease)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Promote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Promote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Bump(t *testing.T) {
+	channels := Channels{"alpha", "beta", "rc"}
+	tests := []struct {
+		name    string
+		latest  *semver.Version
+		opts    BumpOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "next pre-release of the same channel",
+			latest: semver.MustParse("v1.2.0-beta.3"),
+			opts: BumpOptions{
+				Type:             SemVerBumpTypeNone,
+				PreRelease:       true,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "beta",
+				Channels:         channels,
+			},
+			want: "1.2.0-beta.4",
+		},
+		{
+			name:   "move up a channel",
+			latest: semver.MustParse("v1.2.0-beta.3"),
+			opts: BumpOptions{
+				Type:             SemVerBumpTypeNone,
+				PreRelease:       true,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "rc",
+				Channels:         channels,
+			},
+			want: "1.2.0-rc.1",
+		},
+		{
+			name:   "move down a channel",
+			latest: semver.MustParse("v1.2.0-rc.1"),
+			opts: BumpOptions{
+				Type:             SemVerBumpTypeNone,
+				PreRelease:       true,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "alpha",
+				Channels:         channels,
+			},
+			wantErr: true,
+		},
+		{
+			name:   "lower channel of a new version",
+			latest: semver.MustParse("v1.2.0-rc.1"),
+			opts: BumpOptions{
+				Type:             SemVerBumpTypeMinor,
+				PreRelease:       true,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "alpha",
+				Channels:         channels,
+			},
+			want: "1.3.0-alpha.1",
+		},
+		{
+			name:   "unknown channel",
+			latest: semver.MustParse("v1.2.0"),
+			opts: BumpOptions{
+				Type:             SemVerBumpTypePatch,
+				PreRelease:       true,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "nightly",
+				Channels:         channels,
+			},
+			wantErr: true,
+		},
+		{
+			name:   "invalid pre-release prefix",
+			latest: semver.MustParse("v1.2.0"),
+			opts: BumpOptions{
+				Type:             SemVerBumpTypePatch,
+				PreRelease:       true,
+				PreReleaseFormat: PreReleaseFormatSemVer,
+				PreReleasePrefix: "rc_1",
+			},
+			wantErr: true,
+		},
+		{
+			name:   "build metadata",
+			latest: semver.MustParse("v1.2.0+sha.0000000"),
+			opts: BumpOptions{
+				Type:     SemVerBumpTypeMinor,
+				Metadata: "sha.abc1234",
+			},
+			want: "1.3.0+sha.abc1234",
+		},
+		{
+			name:   "keep version without metadata",
+			latest: semver.MustParse("v1.2.0+sha.0000000"),
+			opts: BumpOptions{
+				Type: SemVerBumpTypeNone,
+			},
+			want: "1.2.0",
+		},
+		{
+			name:   "release",
+			latest: semver.MustParse("v1.2.0-rc.1"),
+			opts: BumpOptions{
+				Type:     SemVerBumpTypePatch,
+				Channels: channels,
+			},
+			want: "1.2.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Bump(tt.latest, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Bump() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Bump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithScheme(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	for _, tag := range []string{"v99.1.1", "26.09.4", "26.10.0", "26.10.1-rc.1"} {
+		testTag(t, repo, tag, hashes[0], false)
+	}
+	calVer, err := NewCalVer("YY.0M.MICRO")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		scheme       Scheme
+		isPreRelease bool
+		want         string
+	}{
+		{
+			name:   "semver",
+			scheme: SemVer{},
+			want:   "v99.1.1",
+		},
+		{
+			name:   "calver",
+			scheme: calVer,
+			want:   "26.10.0",
+		},
+		{
+			name:         "calver pre-release",
+			scheme:       calVer,
+			isPreRelease: true,
+			want:         "26.10.1-rc.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLatestSemVerTagFromRepo(repo, tt.isPreRelease, WithScheme(tt.scheme))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Original() != tt.want {
+				t.Errorf("GetLatestSemVerTagFromRepo() = %v, want %v", got.Original(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithTrace(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	for _, tag := range []string{"v1.0.0", "v1.1.0-rc.1", "latest"} {
+		testTag(t, repo, tag, hashes[0], false)
+	}
+
+	trace := &Trace{}
+	if _, err := GetLatestSemVerTagFromRepo(repo, false, WithTrace(trace)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`chose "v1.0.0" as latest tag: it is the highest version that is not a pre-release`,
+		`found tag "v1.0.0"`,
+		`found tag "v1.1.0-rc.1"`,
+		`skipped tag "latest": not a semver version`,
+	}
+	// the order in which the tags are found is not defined
+	sort.Strings(trace.Steps)
+	if !reflect.DeepEqual(trace.Steps, want) {
+		t.Errorf("Trace.Steps = %q, want %q", trace.Steps, want)
+	}
+}
+
+func Test_GetLatestSemVerTagFromRepo_WithRequiredTag(t *testing.T) {
+	repo, hashes := testRepo(t, 1)
+	testTag(t, repo, "v1.1.0-rc.1", hashes[0], false)
+
+	tests := []struct {
+		name         string
+		isPreRelease bool
+		want         string
+		wantErr      error
+	}{
+		{
+			name:         "pre-release",
+			isPreRelease: true,
+			want:         "v1.1.0-rc.1",
+		},
+		{
+			name:         "no release",
+			isPreRelease: false,
+			wantErr:      ErrNoTags,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLatestSemVerTagFromRepo(repo, tt.isPreRelease, WithRequiredTag())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetLatestSemVerTagFromRepo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Original() != tt.want {
+				t.Errorf("GetLatestSemVerTagFromRepo() = %v, want %v", got.Original(), tt.want)
+			}
+		})
+	}
+}
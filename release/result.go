@@ -0,0 +1,63 @@
+package release
+
+import (
+	"github.com/Masterminds/semver/v3"
+)
+
+// Result describes a computed release. It is the machine-readable output of
+// git-tag-bump and can be consumed with tools like jq.
+type Result struct {
+	// PreviousTag is the tag the new version is based on.
+	PreviousTag string `json:"previousTag"`
+	// Tag is the name of the new tag including the tag prefix.
+	Tag string `json:"tag"`
+	// Version is the new version including the build metadata, without v or tag prefix.
+	Version    string         `json:"version"`
+	Major      uint64         `json:"major"`
+	Minor      uint64         `json:"minor"`
+	Patch      uint64         `json:"patch"`
+	PreRelease string         `json:"prerelease"`
+	Metadata   string         `json:"metadata"`
+	BumpType   SemVerBumpType `json:"bumpType"`
+	// Reason explains why the bump type was chosen.
+	Reason string `json:"reason"`
+	// Commit is the hash of the tagged commit.
+	Commit string `json:"commit"`
+	// Existing is true if the commit already had a tag of the version scheme,
+	// which is returned instead of a new version.
+	Existing bool `json:"existing"`
+	Created  bool `json:"created"`
+	// Pushed is true if the tag was pushed to the primary remote.
+	Pushed bool `json:"pushed"`
+	// Remotes are the results of the push to each remote, starting with the
+	// primary remote. It is only set if the tag is created.
+	Remotes []RemoteResult `json:"remotes,omitempty"`
+	// Changelog is the rendered changelog section. It is only set if a
+	// changelog is written.
+	Changelog string `json:"changelog,omitempty"`
+	// Explain is the decision trace. It is only set in explain mode.
+	Explain []string `json:"explain,omitempty"`
+}
+
+// RemoteResult is the result of the push of the tag to a remote.
+type RemoteResult struct {
+	// Remote is the name or URL of the remote.
+	Remote string `json:"remote"`
+	Pushed bool   `json:"pushed"`
+	// RolledBack is true if the pushed tag was deleted again, as the push to
+	// another remote failed in atomic mode.
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// Error is the reason the push failed.
+	Error string `json:"error,omitempty"`
+}
+
+// SetVersion sets the version fields of the result. The version string is
+// formatted by the given scheme.
+func (r *Result) SetVersion(scheme Scheme, v *semver.Version) {
+	r.Version = scheme.Format(v)
+	r.Major = v.Major()
+	r.Minor = v.Minor()
+	r.Patch = v.Patch()
+	r.PreRelease = v.Prerelease()
+	r.Metadata = v.Metadata()
+}
@@ -0,0 +1,49 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestResult_SetVersion(t *testing.T) {
+	calVer, err := NewCalVer("YY.0M.MICRO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type args struct {
+		scheme  Scheme
+		version string
+	}
+	tests := []struct {
+		name string
+		args args
+		want Result
+	}{
+		{
+			name: "release",
+			args: args{scheme: SemVer{}, version: "v1.2.3"},
+			want: Result{Version: "1.2.3", Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			name: "pre-release with metadata",
+			args: args{scheme: SemVer{}, version: "v1.2.3-rc.1+sha.abc1234"},
+			want: Result{Version: "1.2.3-rc.1+sha.abc1234", Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.1", Metadata: "sha.abc1234"},
+		},
+		{
+			name: "calver",
+			args: args{scheme: calVer, version: "26.3.1"},
+			want: Result{Version: "26.03.1", Major: 26, Minor: 3, Patch: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Result{}
+			got.SetVersion(tt.args.scheme, semver.MustParse(tt.args.version))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Result.SetVersion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
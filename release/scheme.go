@@ -0,0 +1,223 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	ErrSchemeMismatch = fmt.Errorf("tag does not match the version scheme")
+	ErrSchemeFormat   = fmt.Errorf("version scheme format is invalid")
+
+	semVerRegEx = regexp.MustCompile(`^[v]{0,1}[0-9]{1,}.[0-9]{1,}.[0-9]{1,}(-[a-zA-Z0-9.-]+){0,1}(\+[a-zA-Z0-9.-]+){0,1}$`)
+)
+
+// Scheme is a versioning scheme. It defines which tags are versions of the
+// scheme, how the version core is bumped and how versions are formatted.
+// Pre-releases and build metadata are handled independently of the scheme.
+type Scheme interface {
+	// String returns the name of the scheme.
+	String() string
+	// Parse parses a tag without tag prefix. If the tag is not a version of
+	// the scheme, ErrSchemeMismatch is returned.
+	Parse(tag string) (*semver.Version, error)
+	// Bump returns the version with the bumped version core. If the bump type
+	// is SemVerBumpTypeNone, the version is returned unchanged.
+	Bump(latest *semver.Version, bumpType SemVerBumpType) semver.Version
+	// Format returns the version as string without a v prefix.
+	Format(v *semver.Version) string
+}
+
+// ParseScheme returns the scheme with the given name. The format is only used
+// by the calver scheme, e.g. YYYY.0M.MICRO.
+func ParseScheme(name, format string) (Scheme, error) {
+	switch name {
+	case "", "semver":
+		return SemVer{}, nil
+	case "calver":
+		return NewCalVer(format)
+	}
+	return nil, fmt.Errorf("%w: unknown scheme %q", ErrSchemeFormat, name)
+}
+
+// SemVer is the semantic versioning scheme (https://semver.org).
+type SemVer struct{}
+
+func (SemVer) String() string {
+	return "semver"
+}
+
+// Parse parses a tag in the format [v]MAJOR.MINOR.PATCH[-PRERELEASE][+METADATA].
+func (SemVer) Parse(tag string) (*semver.Version, error) {
+	if !semVerRegEx.MatchString(tag) {
+		return nil, fmt.Errorf("%w: %q is not a semver version", ErrSchemeMismatch, tag)
+	}
+	return semver.NewVersion(tag)
+}
+
+// Bump increments the segment of the bump type and resets the lower ones.
+func (SemVer) Bump(latest *semver.Version, bumpType SemVerBumpType) semver.Version {
+	formattedLatest := semver.New(latest.Major(), latest.Minor(), latest.Patch(), "", "")
+	switch bumpType {
+	case SemVerBumpTypeMajor:
+		return formattedLatest.IncMajor()
+	case SemVerBumpTypeMinor:
+		return formattedLatest.IncMinor()
+	case SemVerBumpTypePatch:
+		return formattedLatest.IncPatch()
+	}
+	return *latest
+}
+
+func (SemVer) Format(v *semver.Version) string {
+	return v.String()
+}
+
+// calVerSegments maps the supported calendar versioning segments to the
+// regular expression matching them.
+var calVerSegments = map[string]string{
+	"YYYY":  `\d{4}`,
+	"YY":    `\d{1,3}`,
+	"0Y":    `\d{2,3}`,
+	"MM":    `\d{1,2}`,
+	"0M":    `\d{2}`,
+	"WW":    `\d{1,2}`,
+	"0W":    `\d{2}`,
+	"DD":    `\d{1,2}`,
+	"0D":    `\d{2}`,
+	"MINOR": `\d+`,
+	"MICRO": `\d+`,
+}
+
+// CalVer is the calendar versioning scheme (https://calver.org).
+// The format consists of three segments: a date segment, a date or MINOR
+// segment and the MICRO segment, e.g. YYYY.MM.MICRO or YY.0M.MICRO.
+//
+// Whenever the date segments change, they roll over to the current date and
+// the counters are reset. Otherwise a major or minor bump increments MINOR if
+// the format has one, and all other bumps increment MICRO.
+type CalVer struct {
+	format   string
+	segments []string
+	regex    *regexp.Regexp
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewCalVer returns a calendar versioning scheme with the given format.
+func NewCalVer(format string) (*CalVer, error) {
+	segments := strings.Split(format, ".")
+	if len(segments) != 3 {
+		return nil, fmt.Errorf("%w: %q must have three segments", ErrSchemeFormat, format)
+	}
+	patterns := []string{}
+	for i, segment := range segments {
+		pattern, ok := calVerSegments[segment]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: unknown segment %q", ErrSchemeFormat, segment)
+		case i == 0 && (segment == "MINOR" || segment == "MICRO"):
+			return nil, fmt.Errorf("%w: %q must start with a date segment", ErrSchemeFormat, format)
+		case i == 1 && segment == "MICRO":
+			return nil, fmt.Errorf("%w: %q must only end with MICRO", ErrSchemeFormat, format)
+		case i == 2 && segment != "MICRO":
+			return nil, fmt.Errorf("%w: %q must end with MICRO", ErrSchemeFormat, format)
+		}
+		patterns = append(patterns, "("+pattern+")")
+	}
+	return &CalVer{
+		format:   format,
+		segments: segments,
+		regex:    regexp.MustCompile(`^[v]{0,1}` + strings.Join(patterns, `\.`) + `(-[a-zA-Z0-9.-]+){0,1}(\+[a-zA-Z0-9.-]+){0,1}$`),
+		Now:      time.Now,
+	}, nil
+}
+
+func (c *CalVer) String() string {
+	return "calver " + c.format
+}
+
+// Parse parses a tag in the format of the scheme with an optional v prefix,
+// pre-release and build metadata.
+func (c *CalVer) Parse(tag string) (*semver.Version, error) {
+	if !c.regex.MatchString(tag) {
+		return nil, fmt.Errorf("%w: %q is not a %s version", ErrSchemeMismatch, tag, c)
+	}
+	return semver.NewVersion(tag)
+}
+
+// Bump rolls the date segments over to the current date. If the date did
+// not change, MINOR or MICRO are incremented depending on the bump type.
+func (c *CalVer) Bump(latest *semver.Version, bumpType SemVerBumpType) semver.Version {
+	if bumpType.Compare(SemVerBumpTypeNone) == 0 {
+		return *latest
+	}
+	now := c.Now()
+	core := []uint64{latest.Major(), latest.Minor(), latest.Patch()}
+	next := []uint64{0, 0, 0}
+	dateChanged := false
+	for i, segment := range c.segments {
+		if segment == "MINOR" || segment == "MICRO" {
+			continue
+		}
+		next[i] = calVerDateValue(segment, now)
+		if next[i] != core[i] {
+			dateChanged = true
+		}
+	}
+	switch {
+	case dateChanged:
+		// counters are reset after the roll over
+	case c.segments[1] == "MINOR" && bumpType.Compare(SemVerBumpTypePatch) > 0:
+		next[1] = core[1] + 1
+	default:
+		if c.segments[1] == "MINOR" {
+			next[1] = core[1]
+		}
+		next[2] = core[2] + 1
+	}
+	return *semver.New(next[0], next[1], next[2], "", "")
+}
+
+// Format returns the version with zero padded segments if required by the format.
+func (c *CalVer) Format(v *semver.Version) string {
+	core := []uint64{v.Major(), v.Minor(), v.Patch()}
+	parts := []string{}
+	for i, segment := range c.segments {
+		if strings.HasPrefix(segment, "0") {
+			parts = append(parts, fmt.Sprintf("%02d", core[i]))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d", core[i]))
+	}
+	version := strings.Join(parts, ".")
+	if v.Prerelease() != "" {
+		version += "-" + v.Prerelease()
+	}
+	if v.Metadata() != "" {
+		version += "+" + v.Metadata()
+	}
+	return version
+}
+
+// calVerDateValue returns the value of a date segment for the given time.
+func calVerDateValue(segment string, t time.Time) uint64 {
+	switch segment {
+	case "YYYY":
+		return uint64(t.Year())
+	case "YY", "0Y":
+		return uint64(t.Year() - 2000)
+	case "MM", "0M":
+		return uint64(t.Month())
+	case "WW", "0W":
+		_, week := t.ISOWeek()
+		return uint64(week)
+	case "DD", "0D":
+		return uint64(t.Day())
+	}
+	return 0
+}
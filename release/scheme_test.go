@@ -0,0 +1,298 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestParseScheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			scheme: "",
+			want:   "semver",
+		},
+		{
+			name:   "semver",
+			scheme: "semver",
+			want:   "semver",
+		},
+		{
+			name:   "calver",
+			scheme: "calver",
+			format: "YY.0M.MICRO",
+			want:   "calver YY.0M.MICRO",
+		},
+		{
+			name:    "calver with invalid format",
+			scheme:  "calver",
+			format:  "YYYY.MM",
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			scheme:  "romver",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScheme(tt.scheme, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseScheme() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseScheme() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemVer_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "with v prefix",
+			tag:  "v1.2.3",
+			want: "1.2.3",
+		},
+		{
+			name: "pre-release with metadata",
+			tag:  "1.2.3-rc.1+sha.abc1234",
+			want: "1.2.3-rc.1+sha.abc1234",
+		},
+		{
+			name:    "not a version",
+			tag:     "latest",
+			wantErr: true,
+		},
+		{
+			name:    "missing patch",
+			tag:     "v1.2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SemVer{}.Parse(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SemVer.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("SemVer.Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCalVer(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{
+			name:   "year month micro",
+			format: "YYYY.MM.MICRO",
+		},
+		{
+			name:   "short year zero padded month micro",
+			format: "YY.0M.MICRO",
+		},
+		{
+			name:   "year minor micro",
+			format: "YYYY.MINOR.MICRO",
+		},
+		{
+			name:    "two segments",
+			format:  "YYYY.MICRO",
+			wantErr: true,
+		},
+		{
+			name:    "unknown segment",
+			format:  "YYYY.MONTH.MICRO",
+			wantErr: true,
+		},
+		{
+			name:    "no date segment",
+			format:  "MINOR.MM.MICRO",
+			wantErr: true,
+		},
+		{
+			name:    "no micro segment",
+			format:  "YYYY.MM.DD",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCalVer(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("NewCalVer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCalVer_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "year month micro",
+			format: "YYYY.MM.MICRO",
+			tag:    "2026.10.3",
+			want:   "2026.10.3",
+		},
+		{
+			name:   "zero padded month",
+			format: "YY.0M.MICRO",
+			tag:    "26.01.0-rc.1",
+			want:   "26.1.0-rc.1",
+		},
+		{
+			name:    "missing zero padding",
+			format:  "YY.0M.MICRO",
+			tag:     "26.1.0",
+			wantErr: true,
+		},
+		{
+			name:    "semver tag",
+			format:  "YYYY.MM.MICRO",
+			tag:     "v1.2.3",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCalVer(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.Parse(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CalVer.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("CalVer.Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalVer_Bump(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		format   string
+		latest   string
+		bumpType SemVerBumpType
+		want     string
+	}{
+		{
+			name:     "same month",
+			format:   "YYYY.MM.MICRO",
+			latest:   "2026.10.3",
+			bumpType: SemVerBumpTypePatch,
+			want:     "2026.10.4",
+		},
+		{
+			name:     "new month resets micro",
+			format:   "YYYY.MM.MICRO",
+			latest:   "2026.9.3",
+			bumpType: SemVerBumpTypePatch,
+			want:     "2026.10.0",
+		},
+		{
+			name:     "major in same month",
+			format:   "YYYY.MM.MICRO",
+			latest:   "2026.10.3",
+			bumpType: SemVerBumpTypeMajor,
+			want:     "2026.10.4",
+		},
+		{
+			name:     "short year",
+			format:   "YY.0M.MICRO",
+			latest:   "25.12.1",
+			bumpType: SemVerBumpTypeMinor,
+			want:     "26.10.0",
+		},
+		{
+			name:     "minor segment",
+			format:   "YYYY.MINOR.MICRO",
+			latest:   "2026.2.5",
+			bumpType: SemVerBumpTypeMinor,
+			want:     "2026.3.0",
+		},
+		{
+			name:     "micro with minor segment",
+			format:   "YYYY.MINOR.MICRO",
+			latest:   "2026.2.5",
+			bumpType: SemVerBumpTypePatch,
+			want:     "2026.2.6",
+		},
+		{
+			name:     "new year resets minor",
+			format:   "YYYY.MINOR.MICRO",
+			latest:   "2025.7.5",
+			bumpType: SemVerBumpTypePatch,
+			want:     "2026.0.0",
+		},
+		{
+			name:     "no tag yet",
+			format:   "YYYY.MM.MICRO",
+			latest:   "0.0.0",
+			bumpType: SemVerBumpTypePatch,
+			want:     "2026.10.0",
+		},
+		{
+			name:     "none",
+			format:   "YYYY.MM.MICRO",
+			latest:   "2025.1.0-rc.1",
+			bumpType: SemVerBumpTypeNone,
+			want:     "2025.1.0-rc.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCalVer(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Now = func() time.Time { return now }
+			got := c.Bump(semver.MustParse(tt.latest), tt.bumpType)
+			if got.String() != tt.want {
+				t.Errorf("CalVer.Bump() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCalVer_Format(t *testing.T) {
+	c, err := NewCalVer("YY.0M.MICRO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := c.Format(semver.MustParse("26.1.0-rc.1+sha.abc1234"))
+	if want := "26.01.0-rc.1+sha.abc1234"; got != want {
+		t.Errorf("CalVer.Format() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,17 @@
+package release
+
+import "fmt"
+
+// Trace records the decisions taken while computing a new version, e.g. which
+// tags were found or skipped. A nil Trace discards all records.
+type Trace struct {
+	Steps []string
+}
+
+// Addf records a step of the decision trace.
+func (t *Trace) Addf(format string, args ...any) {
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,20 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrace_Addf(t *testing.T) {
+	trace := &Trace{}
+	trace.Addf("found tag %q", "v1.0.0")
+	trace.Addf("chose %s", "v1.0.0")
+	want := []string{`found tag "v1.0.0"`, "chose v1.0.0"}
+	if !reflect.DeepEqual(trace.Steps, want) {
+		t.Errorf("Trace.Steps = %v, want %v", trace.Steps, want)
+	}
+
+	// a nil trace discards all records
+	var nilTrace *Trace
+	nilTrace.Addf("found tag %q", "v1.0.0")
+}
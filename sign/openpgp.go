@@ -0,0 +1,62 @@
+package sign
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// OpenPGP signs with an OpenPGP private key. The signatures are armored
+// detached signatures, as created by gpg.
+type OpenPGP struct {
+	entity *openpgp.Entity
+}
+
+// NewOpenPGP returns the signer of the first private key in the armored key
+// ring. Encrypted keys are decrypted with the passphrase.
+func NewOpenPGP(armoredKey, passphrase []byte) (*OpenPGP, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKey, err)
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			if len(passphrase) == 0 {
+				return nil, fmt.Errorf("%w: the openpgp key is encrypted, but no passphrase is given", ErrKey)
+			}
+			if err := entity.DecryptPrivateKeys(passphrase); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrKey, err)
+			}
+		}
+		return &OpenPGP{entity: entity}, nil
+	}
+	return nil, fmt.Errorf("%w: no openpgp private key found", ErrKey)
+}
+
+// Sign returns the armored detached signature of the message.
+func (o *OpenPGP) Sign(message io.Reader) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := openpgp.ArmoredDetachSign(buf, o.entity, message, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Verify checks the armored detached signature against the key of the signer.
+func (o *OpenPGP) Verify(message, signature []byte) error {
+	_, err := openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{o.entity}, bytes.NewReader(message), bytes.NewReader(signature), nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSignature, err)
+	}
+	return nil
+}
+
+// Format returns FormatOpenPGP.
+func (o *OpenPGP) Format() Format {
+	return FormatOpenPGP
+}
@@ -0,0 +1,107 @@
+package sign
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewOpenPGP(t *testing.T) {
+	encrypted := testOpenPGPKey(t, "secret")
+	type args struct {
+		key        []byte
+		passphrase string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "plain key",
+			args: args{key: testOpenPGPKey(t, "")},
+		},
+		{
+			name: "encrypted key",
+			args: args{key: encrypted, passphrase: "secret"},
+		},
+		{
+			name:    "encrypted key without passphrase",
+			args:    args{key: encrypted},
+			wantErr: ErrKey,
+		},
+		{
+			name:    "wrong passphrase",
+			args:    args{key: encrypted, passphrase: "wrong"},
+			wantErr: ErrKey,
+		},
+		{
+			name:    "no key",
+			args:    args{key: []byte("not a key")},
+			wantErr: ErrKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOpenPGP(tt.args.key, []byte(tt.args.passphrase))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewOpenPGP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenPGP_Verify(t *testing.T) {
+	signer, err := NewOpenPGP(testOpenPGPKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewOpenPGP(testOpenPGPKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("object 0000000000000000000000000000000000000000\n")
+	signature, err := signer.Sign(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(signature), "-----BEGIN PGP SIGNATURE-----") {
+		t.Errorf("OpenPGP.Sign() = %q, want an armored signature", signature)
+	}
+
+	type args struct {
+		message   []byte
+		signature []byte
+	}
+	tests := []struct {
+		name    string
+		signer  *OpenPGP
+		args    args
+		wantErr error
+	}{
+		{
+			name:   "valid signature",
+			signer: signer,
+			args:   args{message: message, signature: signature},
+		},
+		{
+			name:    "modified message",
+			signer:  signer,
+			args:    args{message: []byte("modified"), signature: signature},
+			wantErr: ErrSignature,
+		},
+		{
+			name:    "another key",
+			signer:  other,
+			args:    args{message: message, signature: signature},
+			wantErr: ErrSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.signer.Verify(tt.args.message, tt.args.signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("OpenPGP.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+)
+
+var (
+	ErrFormat    = fmt.Errorf("unknown signature format")
+	ErrKey       = fmt.Errorf("signing key is invalid")
+	ErrSignature = fmt.Errorf("signature is invalid")
+)
+
+// Format is the format of a tag signature, similar to the gpg.format option
+// of git.
+type Format string
+
+const (
+	// FormatOpenPGP signs with an OpenPGP key, like gpg.format=openpgp.
+	FormatOpenPGP Format = "openpgp"
+	// FormatSSH signs with an SSH key, like gpg.format=ssh.
+	FormatSSH Format = "ssh"
+)
+
+func (f Format) String() string {
+	return string(f)
+}
+
+// Signer signs git objects and verifies its own signatures. It implements
+// git.Signer.
+type Signer interface {
+	git.Signer
+	// Verify returns ErrSignature if the signature does not match the message
+	// or was not made by the key of the signer.
+	Verify(message, signature []byte) error
+	// Format returns the format of the signatures.
+	Format() Format
+}
+
+// New returns the signer of the given format for the private key. The
+// passphrase is only used for encrypted keys.
+func New(format Format, key, passphrase []byte) (Signer, error) {
+	switch format {
+	case FormatOpenPGP:
+		return NewOpenPGP(key, passphrase)
+	case FormatSSH:
+		return NewSSH(key, passphrase)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrFormat, format)
+}
+
+// CreateTag creates a signed annotated tag for the target object. The message
+// is canonicalized like git does. If the tag exists, release.ErrTagExists is
+// returned.
+func CreateTag(repo *git.Repository, name string, target plumbing.Hash, tagger *object.Signature, message string, signer Signer) (*plumbing.Reference, error) {
+	refName := plumbing.NewTagReferenceName(name)
+	_, err := repo.Reference(refName, false)
+	switch {
+	case err == nil:
+		return nil, fmt.Errorf("%w: %q", release.ErrTagExists, name)
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil, err
+	}
+
+	rawobj, err := repo.Storer.EncodedObject(plumbing.AnyObject, target)
+	if err != nil {
+		return nil, err
+	}
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: rawobj.Type(),
+		Target:     target,
+	}
+
+	// the signature covers the encoded tag object without signature
+	payload, err := encodeWithoutSignature(tag)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("could not sign tag %q: %w", name, err)
+	}
+	tag.PGPSignature = string(signature)
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		return nil, err
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	ref := plumbing.NewHashReference(refName, hash)
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// VerifyTag reads the tag object of the reference from the repository and
+// verifies its signature with the signer. Unsigned tags return ErrSignature.
+func VerifyTag(repo *git.Repository, ref *plumbing.Reference, signer Signer) error {
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		return err
+	}
+	if tag.PGPSignature == "" {
+		return fmt.Errorf("%w: tag %q is not signed", ErrSignature, tag.Name)
+	}
+	payload, err := encodeWithoutSignature(tag)
+	if err != nil {
+		return err
+	}
+	if err := signer.Verify(payload, []byte(tag.PGPSignature)); err != nil {
+		return fmt.Errorf("tag %q: %w", tag.Name, err)
+	}
+	return nil
+}
+
+// encodeWithoutSignature returns the encoded tag object without signature,
+// which is the payload of the signature.
+func encodeWithoutSignature(tag *object.Tag) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(encoded); err != nil {
+		return nil, err
+	}
+	r, err := encoded.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
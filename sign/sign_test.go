@@ -0,0 +1,241 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/leonsteinhaeuser/git-tag-bump/release"
+	"golang.org/x/crypto/ssh"
+)
+
+var testSignature = &object.Signature{Name: "test", Email: "test@example.com", When: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}
+
+// testOpenPGPKey returns a new armored OpenPGP private key. If passphrase is
+// set, the key is encrypted.
+func testOpenPGPKey(t *testing.T, passphrase string) []byte {
+	t.Helper()
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passphrase != "" {
+		if err := entity.EncryptPrivateKeys([]byte(passphrase), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := &bytes.Buffer{}
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the self-signatures are already made, so the encrypted key can be serialized
+	if err := entity.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testSSHKey returns a new PEM encoded ed25519 private key. If passphrase is
+// set, the key is encrypted.
+func testSSHKey(t *testing.T, passphrase string) []byte {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+// testRepo returns an in-memory repository with one commit.
+func testRepo(t *testing.T) (*git.Repository, plumbing.Hash) {
+	t.Helper()
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := &object.Tree{}
+	treeObj := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(treeObj); err != nil {
+		t.Fatal(err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &object.Commit{Author: *testSignature, Committer: *testSignature, Message: "initial commit", TreeHash: treeHash}
+	commitObj := repo.Storer.NewEncodedObject()
+	if err := c.Encode(commitObj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(commitObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, hash
+}
+
+func TestNew(t *testing.T) {
+	type args struct {
+		format Format
+		key    []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "openpgp",
+			args: args{format: FormatOpenPGP, key: testOpenPGPKey(t, "")},
+		},
+		{
+			name: "ssh",
+			args: args{format: FormatSSH, key: testSSHKey(t, "")},
+		},
+		{
+			name:    "ssh key as openpgp key",
+			args:    args{format: FormatOpenPGP, key: testSSHKey(t, "")},
+			wantErr: ErrKey,
+		},
+		{
+			name:    "unknown format",
+			args:    args{format: "x509", key: testSSHKey(t, "")},
+			wantErr: ErrFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.args.format, tt.args.key, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Format() != tt.args.format {
+				t.Errorf("New().Format() = %v, want %v", got.Format(), tt.args.format)
+			}
+		})
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	openPGP, err := NewOpenPGP(testOpenPGPKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshSigner, err := NewSSH(testSSHKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		signer Signer
+	}{
+		{
+			name:   "openpgp",
+			signer: openPGP,
+		},
+		{
+			name:   "ssh",
+			signer: sshSigner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, target := testRepo(t)
+			ref, err := CreateTag(repo, "v1.0.0", target, testSignature, "Release v1.0.0", tt.signer)
+			if err != nil {
+				t.Fatalf("CreateTag() error = %v", err)
+			}
+			if err := VerifyTag(repo, ref, tt.signer); err != nil {
+				t.Errorf("VerifyTag() error = %v", err)
+			}
+
+			tag, err := repo.TagObject(ref.Hash())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tag.Message != "Release v1.0.0\n" || tag.Target != target {
+				t.Errorf("CreateTag() message = %q, target = %v, want %q, %v", tag.Message, tag.Target, "Release v1.0.0\n", target)
+			}
+
+			if _, err := CreateTag(repo, "v1.0.0", target, testSignature, "Release v1.0.0", tt.signer); !errors.Is(err, release.ErrTagExists) {
+				t.Errorf("CreateTag() error = %v, wantErr %v", err, release.ErrTagExists)
+			}
+		})
+	}
+}
+
+func TestVerifyTag(t *testing.T) {
+	signer, err := NewSSH(testSSHKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewSSH(testSSHKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, target := testRepo(t)
+	signed, err := CreateTag(repo, "v1.0.0", target, testSignature, "v1.0.0", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := repo.CreateTag("v1.0.1", target, &git.CreateTagOptions{Tagger: testSignature, Message: "v1.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     *plumbing.Reference
+		signer  Signer
+		wantErr error
+	}{
+		{
+			name:   "signed by the signer",
+			ref:    signed,
+			signer: signer,
+		},
+		{
+			name:    "signed by another key",
+			ref:     signed,
+			signer:  other,
+			wantErr: ErrSignature,
+		},
+		{
+			name:    "unsigned",
+			ref:     unsigned,
+			signer:  signer,
+			wantErr: ErrSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyTag(repo, tt.ref, tt.signer); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// the constants of the SSH signature format, see
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+const (
+	sshSigMagic     = "SSHSIG"
+	sshSigVersion   = 1
+	sshSigNamespace = "git"
+	sshSigHash      = "sha512"
+	sshSigBegin     = "-----BEGIN SSH SIGNATURE-----"
+	sshSigEnd       = "-----END SSH SIGNATURE-----"
+	// ssh-keygen wraps the armored signature after 70 characters
+	sshSigLineLength = 70
+)
+
+// SSH signs with an SSH private key. The signatures are armored SSH
+// signatures of the "git" namespace, as created by ssh-keygen -Y sign.
+type SSH struct {
+	signer ssh.Signer
+}
+
+// NewSSH returns the signer of the PEM encoded private key. Encrypted keys
+// are decrypted with the passphrase.
+func NewSSH(privateKey, passphrase []byte) (*SSH, error) {
+	var signer ssh.Signer
+	var err error
+	if len(passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(privateKey, passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(privateKey)
+	}
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &missing):
+		return nil, fmt.Errorf("%w: the ssh key is encrypted, but no passphrase is given", ErrKey)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", ErrKey, err)
+	}
+	return &SSH{signer: signer}, nil
+}
+
+// sshSignedData is the data signed by the key. It binds the hash of the
+// message to the namespace.
+type sshSignedData struct {
+	Magic     [6]byte
+	Namespace string
+	Reserved  string
+	Hash      string
+	Digest    string
+}
+
+// sshSignature is the blob of an armored SSH signature.
+type sshSignature struct {
+	Magic     [6]byte
+	Version   uint32
+	PublicKey string
+	Namespace string
+	Reserved  string
+	Hash      string
+	Signature string
+}
+
+// Sign returns the armored SSH signature of the message.
+func (s *SSH) Sign(message io.Reader) ([]byte, error) {
+	data, err := sshData(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var sig *ssh.Signature
+	// RSA keys must not use SHA-1 signatures
+	if signer, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = signer.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob := ssh.Marshal(sshSignature{
+		Magic:     [6]byte([]byte(sshSigMagic)),
+		Version:   sshSigVersion,
+		PublicKey: string(s.signer.PublicKey().Marshal()),
+		Namespace: sshSigNamespace,
+		Hash:      sshSigHash,
+		Signature: string(ssh.Marshal(sig)),
+	})
+	return armorSSH(blob), nil
+}
+
+// Verify checks the armored SSH signature against the key of the signer.
+func (s *SSH) Verify(message, signature []byte) error {
+	blob, err := dearmorSSH(signature)
+	if err != nil {
+		return err
+	}
+	sig := sshSignature{}
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return fmt.Errorf("%w: %w", ErrSignature, err)
+	}
+	switch {
+	case string(sig.Magic[:]) != sshSigMagic:
+		return fmt.Errorf("%w: unknown magic %q", ErrSignature, sig.Magic[:])
+	case sig.Version != sshSigVersion:
+		return fmt.Errorf("%w: unsupported version %d", ErrSignature, sig.Version)
+	case sig.Namespace != sshSigNamespace:
+		return fmt.Errorf("%w: unexpected namespace %q", ErrSignature, sig.Namespace)
+	case sig.Hash != sshSigHash:
+		return fmt.Errorf("%w: unsupported hash algorithm %q", ErrSignature, sig.Hash)
+	case !bytes.Equal([]byte(sig.PublicKey), s.signer.PublicKey().Marshal()):
+		return fmt.Errorf("%w: made by another key", ErrSignature)
+	}
+
+	signed := &ssh.Signature{}
+	if err := ssh.Unmarshal([]byte(sig.Signature), signed); err != nil {
+		return fmt.Errorf("%w: %w", ErrSignature, err)
+	}
+	data, err := sshData(bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	if err := s.signer.PublicKey().Verify(data, signed); err != nil {
+		return fmt.Errorf("%w: %w", ErrSignature, err)
+	}
+	return nil
+}
+
+// Format returns FormatSSH.
+func (s *SSH) Format() Format {
+	return FormatSSH
+}
+
+// sshData returns the data signed by the key for the message.
+func sshData(message io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, err
+	}
+	return ssh.Marshal(sshSignedData{
+		Magic:     [6]byte([]byte(sshSigMagic)),
+		Namespace: sshSigNamespace,
+		Hash:      sshSigHash,
+		Digest:    string(h.Sum(nil)),
+	}), nil
+}
+
+// armorSSH returns the armored SSH signature of the blob.
+func armorSSH(blob []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	buf := &bytes.Buffer{}
+	buf.WriteString(sshSigBegin + "\n")
+	for len(encoded) > sshSigLineLength {
+		buf.WriteString(encoded[:sshSigLineLength] + "\n")
+		encoded = encoded[sshSigLineLength:]
+	}
+	buf.WriteString(encoded + "\n")
+	buf.WriteString(sshSigEnd + "\n")
+	return buf.Bytes()
+}
+
+// dearmorSSH returns the blob of the armored SSH signature.
+func dearmorSSH(armored []byte) ([]byte, error) {
+	content := strings.TrimSpace(string(armored))
+	content, ok := strings.CutPrefix(content, sshSigBegin)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an armored ssh signature", ErrSignature)
+	}
+	content, ok = strings.CutSuffix(content, sshSigEnd)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an armored ssh signature", ErrSignature)
+	}
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignature, err)
+	}
+	return blob, nil
+}
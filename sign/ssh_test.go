@@ -0,0 +1,140 @@
+package sign
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewSSH(t *testing.T) {
+	encrypted := testSSHKey(t, "secret")
+	type args struct {
+		key        []byte
+		passphrase string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "plain key",
+			args: args{key: testSSHKey(t, "")},
+		},
+		{
+			name: "encrypted key",
+			args: args{key: encrypted, passphrase: "secret"},
+		},
+		{
+			name:    "encrypted key without passphrase",
+			args:    args{key: encrypted},
+			wantErr: ErrKey,
+		},
+		{
+			name:    "wrong passphrase",
+			args:    args{key: encrypted, passphrase: "wrong"},
+			wantErr: ErrKey,
+		},
+		{
+			name:    "no key",
+			args:    args{key: []byte("not a key")},
+			wantErr: ErrKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSSH(tt.args.key, []byte(tt.args.passphrase))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSSH() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSSH_Verify(t *testing.T) {
+	signer, err := NewSSH(testSSHKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewSSH(testSSHKey(t, ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(rsaKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSigner, err := NewSSH(pem.EncodeToMemory(block), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("object 0000000000000000000000000000000000000000\n")
+	signature, err := signer.Sign(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(signature), sshSigBegin+"\n") || !strings.HasSuffix(string(signature), sshSigEnd+"\n") {
+		t.Errorf("SSH.Sign() = %q, want an armored signature", signature)
+	}
+	rsaSignature, err := rsaSigner.Sign(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		message   []byte
+		signature []byte
+	}
+	tests := []struct {
+		name    string
+		signer  *SSH
+		args    args
+		wantErr error
+	}{
+		{
+			name:   "valid signature",
+			signer: signer,
+			args:   args{message: message, signature: signature},
+		},
+		{
+			name:   "valid rsa signature",
+			signer: rsaSigner,
+			args:   args{message: message, signature: rsaSignature},
+		},
+		{
+			name:    "modified message",
+			signer:  signer,
+			args:    args{message: []byte("modified"), signature: signature},
+			wantErr: ErrSignature,
+		},
+		{
+			name:    "another key",
+			signer:  other,
+			args:    args{message: message, signature: signature},
+			wantErr: ErrSignature,
+		},
+		{
+			name:    "not armored",
+			signer:  signer,
+			args:    args{message: message, signature: []byte("signature")},
+			wantErr: ErrSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.signer.Verify(tt.args.message, tt.args.signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SSH.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}